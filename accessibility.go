@@ -0,0 +1,84 @@
+package csv2htmltable
+
+import (
+	"strconv"
+	"strings"
+)
+
+// associates returns whether the table's cells are associated with their
+// header cells using the id and headers attributes.  This is always the case
+// when values are merged, because the merged cells aren't in every row.
+func (h *HTMLTable) associates() bool {
+	return h.HeaderIDs || len(h.mergeIdx) > 0
+}
+
+// idPrefix returns the prefix of the IDs generated for the table's elements:
+// the table's ID, if it has one, otherwise its template's name.
+func (h *HTMLTable) idPrefix() string {
+	if h.ID != "" {
+		return h.ID
+	}
+	return h.tpl.Name()
+}
+
+// columnID returns the ID of the j'th column's own header cell.
+func (h *HTMLTable) columnID(j int) string {
+	return h.idPrefix() + "-c" + strconv.Itoa(j)
+}
+
+// headerID returns the ID of a header cell, in the i'th header row, that
+// starts at the j'th column but isn't the column's own header, e.g. a cell
+// that spans columns.
+func (h *HTMLTable) headerID(i, j int) string {
+	return h.idPrefix() + "-h" + strconv.Itoa(i) + "c" + strconv.Itoa(j)
+}
+
+// rowHeaderID returns the ID of the row header cell in the j'th column of
+// the i'th body row.
+func (h *HTMLTable) rowHeaderID(i, j int) string {
+	return h.idPrefix() + "-r" + strconv.Itoa(i) + "c" + strconv.Itoa(j)
+}
+
+// columnHeaderIDs returns the IDs of the header cells of each column, from
+// the top header row down.
+func (h *HTMLTable) columnHeaderIDs() [][]string {
+	if !h.HasHeader {
+		return nil
+	}
+	ids := make([][]string, h.Cols)
+	for _, row := range h.headerGrid() {
+		for _, c := range row {
+			if c.id == "" {
+				continue
+			}
+			for x := c.col; x < c.col+c.span && x < len(ids); x++ {
+				ids[x] = append(ids[x], c.id)
+			}
+		}
+	}
+	return ids
+}
+
+// descriptionID returns the ID of the table's description.
+func (h *HTMLTable) descriptionID() string {
+	return h.idPrefix() + "-description"
+}
+
+// describedBy returns the value of the table's aria-describedby attribute:
+// the ID of its Description, if it has one, followed by DescribedBy.
+func (h *HTMLTable) describedBy() string {
+	var ids []string
+	if h.Description != "" {
+		ids = append(ids, h.descriptionID())
+	}
+	if h.DescribedBy != "" {
+		ids = append(ids, h.DescribedBy)
+	}
+	return joinIDs(ids)
+}
+
+// joinIDs returns the received IDs as a space separated list, for the
+// headers and aria-describedby attributes.
+func joinIDs(ids []string) string {
+	return strings.Join(ids, " ")
+}
@@ -0,0 +1,187 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	idAttr      = regexp.MustCompile(` id="([^"]+)"`)
+	headersAttr = regexp.MustCompile(`<t[dh][^>]* headers="([^"]+)"[^>]*>([^<]*)<`)
+)
+
+// cellHeaders returns the headers attribute of each cell, keyed by the
+// cell's text, after checking that every ID that a headers attribute
+// references is the ID of an element in the table.
+func cellHeaders(t *testing.T, i int, html string) map[string]string {
+	ids := make(map[string]bool)
+	for _, m := range idAttr.FindAllStringSubmatch(html, -1) {
+		if ids[m[1]] {
+			t.Errorf("%d: duplicate id %q", i, m[1])
+		}
+		ids[m[1]] = true
+	}
+	headers := make(map[string]string)
+	for _, m := range headersAttr.FindAllStringSubmatch(html, -1) {
+		for _, id := range strings.Fields(m[1]) {
+			if !ids[id] {
+				t.Errorf("%d: cell %q: headers references unknown id %q", i, m[2], id)
+			}
+		}
+		headers[m[2]] = m[1]
+	}
+	return headers
+}
+
+func TestHeaderIDs(t *testing.T) {
+	tests := []struct {
+		HeaderRows   [][]string
+		HasRowHeader bool
+		Rows         []Row
+		Expected     map[string]string
+	}{
+		{ // 0
+			HeaderRows: [][]string{
+				[]string{"Name", "Score", "Score"},
+				[]string{"", "Home", "Away"},
+			},
+			HasRowHeader: true,
+			Expected: map[string]string{
+				"Bob":   "t-c0",
+				"3":     "t-h0c1 t-c1 t-r0c0",
+				"1":     "t-h0c1 t-c2 t-r0c0",
+				"Alice": "t-c0",
+				"5":     "t-h0c1 t-c1 t-r1c0",
+				"2":     "t-h0c1 t-c2 t-r1c0",
+			},
+		},
+		{ // 1
+			HeaderRows: [][]string{
+				[]string{"Name", "Home", "Away"},
+			},
+			Expected: map[string]string{
+				"Bob": "t-c0",
+				"3":   "t-c1",
+				"2":   "t-c2",
+			},
+		},
+		{ // 2
+			HeaderRows: [][]string{
+				[]string{"Team", "Player", "Score"},
+			},
+			Rows: []Row{
+				{Cells: []Cell{{Text: "Reds", Header: true, RowSpan: 2}, {Text: "Bob", Header: true}, {Text: "3"}}},
+				{Cells: []Cell{{Text: "Alice", Header: true}, {Text: "5"}}},
+				{Cells: []Cell{{Text: "Total", Header: true, ColSpan: 2}, {Text: "8"}}},
+			},
+			Expected: map[string]string{
+				"Bob":   "t-c1 t-r0c0",
+				"3":     "t-c2 t-r0c0 t-r0c1",
+				"Alice": "t-c1 t-r0c0",
+				"5":     "t-c2 t-r0c0 t-r1c1",
+				"Total": "t-c0 t-c1",
+				"8":     "t-c2 t-r2c0",
+			},
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.ID = "t"
+		h.HeaderIDs = true
+		h.SpanHeaders = true
+		h.HasRowHeader = test.HasRowHeader
+		h.HeaderRowNum = 0
+		h.HeaderRows = test.HeaderRows
+		h.CSV = [][]string{
+			[]string{"Bob", "3", "1"},
+			[]string{"Alice", "5", "2"},
+		}
+		h.Rows = test.Rows
+		err := h.Write(&buf)
+		if err != nil {
+			t.Errorf("%d: got %q: want nil", i, err)
+			continue
+		}
+		headers := cellHeaders(t, i, buf.String())
+		for text, want := range test.Expected {
+			if headers[text] != want {
+				t.Errorf("%d: cell %q: got headers %q; want %q", i, text, headers[text], want)
+			}
+		}
+	}
+}
+
+func TestScope(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.HasRowHeader = true
+	h.Footer = "note"
+	h.FooterRows = []FooterRow{{Label: "Total", Aggregates: map[string]Aggregate{"Score": AggSum}}}
+	h.CSV = [][]string{
+		[]string{"Name", "Score"},
+		[]string{"Bob", "3"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	for _, s := range []string{
+		`<th scope="col">Name</th>`,
+		`<th scope="col">Score</th>`,
+		`<th scope="row">Bob</th>`,
+		`<th scope="row">Total</th>`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected output to contain %q; got %q", s, buf.String())
+		}
+	}
+}
+
+func TestDescription(t *testing.T) {
+	tests := []struct {
+		ID          string
+		Description string
+		DescribedBy string
+		Expected    string
+	}{
+		{ // 0
+			Expected: "\n<table class=\"test\" border=\"\">",
+		},
+		{ // 1
+			ID:          "scores",
+			Description: "Scores by player; higher is better.",
+			Expected:    "\n<p id=\"scores-description\">Scores by player; higher is better.</p>\n<table class=\"test\" id=\"scores\" aria-describedby=\"scores-description\" border=\"\">",
+		},
+		{ // 2
+			Description: "Scores <by> player.",
+			DescribedBy: "notes",
+			Expected:    "\n<p id=\"test-description\">Scores &lt;by&gt; player.</p>\n<table class=\"test\" aria-describedby=\"test-description notes\" border=\"\">",
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.ID = test.ID
+		h.Description = test.Description
+		h.DescribedBy = test.DescribedBy
+		h.CSV = [][]string{
+			[]string{"Name", "Score"},
+			[]string{"Bob", "3"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			t.Errorf("%d: got %q: want nil", i, err)
+			continue
+		}
+		if !strings.HasPrefix(buf.String(), test.Expected) {
+			t.Errorf("%d: got %q; want prefix %q", i, buf.String(), test.Expected)
+		}
+	}
+}
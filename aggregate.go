@@ -0,0 +1,229 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var errUnknownAggregate = errors.New("unknown aggregate")
+
+// Aggregate is a function that summarizes a column's values, e.g. for a
+// subtotal or footer row.
+type Aggregate int
+
+// Supported aggregates.  Sum, mean, and median ignore the values that aren't
+// numbers.  Min and max compare the values according to the column's type.
+const (
+	AggNone     Aggregate = iota
+	AggSum                // the sum of the values
+	AggCount              // the number of values that aren't empty
+	AggMean               // the arithmetic mean of the values
+	AggMedian             // the median of the values
+	AggMin                // the smallest value
+	AggMax                // the largest value
+	AggDistinct           // the number of distinct values that aren't empty
+)
+
+var aggregateNames = [...]string{"", "sum", "count", "mean", "median", "min", "max", "distinct"}
+
+func (a Aggregate) String() string {
+	if a < 0 || int(a) >= len(aggregateNames) {
+		return ""
+	}
+	return aggregateNames[a]
+}
+
+// ParseAggregate returns the Aggregate with the received name, e.g. "sum".
+// "avg" is accepted as an alias for mean.
+func ParseAggregate(s string) (Aggregate, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "avg" {
+		return AggMean, nil
+	}
+	for i, name := range aggregateNames {
+		if name != "" && name == s {
+			return Aggregate(i), nil
+		}
+	}
+	return AggNone, fmt.Errorf("%s: %q", errUnknownAggregate, s)
+}
+
+// ParseAggregates parses a comma separated list of column=aggregate pairs,
+// e.g. "Revenue=sum,Orders=count".
+func ParseAggregates(s string) (map[string]Aggregate, error) {
+	pairs, err := ParseRename(s)
+	if err != nil {
+		return nil, err
+	}
+	aggs := make(map[string]Aggregate, len(pairs))
+	for k, v := range pairs {
+		aggs[k], err = ParseAggregate(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return aggs, nil
+}
+
+// Apply returns the aggregate of the received values, whose type is
+// inferred.
+func (a Aggregate) Apply(values []string) string {
+	return a.apply(values, InferType(values))
+}
+
+// counts returns whether the aggregate is a count, as opposed to a value
+// that is measured in the column's units.
+func (a Aggregate) counts() bool {
+	return a == AggCount || a == AggDistinct
+}
+
+func (a Aggregate) apply(values []string, typ ColumnType) string {
+	switch a {
+	case AggSum, AggMean, AggMedian:
+		nums := numbers(values)
+		if len(nums) == 0 {
+			return ""
+		}
+		var sum float64
+		for _, f := range nums {
+			sum += f
+		}
+		switch a {
+		case AggMean:
+			return formatFloat(sum / float64(len(nums)))
+		case AggMedian:
+			sort.Float64s(nums)
+			m := len(nums) / 2
+			if len(nums)%2 == 0 {
+				return formatFloat((nums[m-1] + nums[m]) / 2)
+			}
+			return formatFloat(nums[m])
+		}
+		return formatFloat(sum)
+	case AggMin, AggMax:
+		var best string
+		var found bool
+		for _, v := range values {
+			k := makeSortKey(v, typ)
+			if k.null {
+				continue
+			}
+			if !found {
+				best, found = v, true
+				continue
+			}
+			c := compareSortKeys(k, makeSortKey(best, typ), typ, nil)
+			if (a == AggMin && c < 0) || (a == AggMax && c > 0) {
+				best = v
+			}
+		}
+		return best
+	case AggCount:
+		var n int
+		for _, v := range values {
+			if !isNull(v) {
+				n++
+			}
+		}
+		return strconv.Itoa(n)
+	case AggDistinct:
+		seen := map[string]bool{}
+		for _, v := range values {
+			if !isNull(v) {
+				seen[v] = true
+			}
+		}
+		return strconv.Itoa(len(seen))
+	}
+	return ""
+}
+
+// numbers returns the values that are numbers.
+func numbers(values []string) []float64 {
+	nums := make([]float64, 0, len(values))
+	for _, v := range values {
+		if f, ok := ParseNumber(v); ok {
+			nums = append(nums, f)
+		}
+	}
+	return nums
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// FooterRow is a computed footer row.  Each aggregated column's cell holds
+// the aggregate of that column's body values, formatted using the column's
+// Formatter, if it has one; counts aren't formatted.
+type FooterRow struct {
+	// The text in the first cell, unless the first column is aggregated.
+	Label string
+	// The aggregate for each column, keyed by the column's header or 0-based
+	// index.  Columns that aren't aggregated are empty.
+	Aggregates map[string]Aggregate
+}
+
+// footRow is a row in the table's footer.
+type footRow struct {
+	Class string
+	Cells []string
+}
+
+// aggregateRow returns a row with the aggregates, keyed by column, of the
+// received records.  If the first column isn't aggregated, it holds the
+// label.
+func (h *HTMLTable) aggregateRow(aggs map[string]Aggregate, records [][]string, label string) ([]string, error) {
+	row := make([]string, h.Cols)
+	if len(row) > 0 {
+		row[0] = label
+	}
+	for k, a := range aggs {
+		i := h.columnIndex(k)
+		if i < 0 {
+			return nil, unknownColumnErr(k)
+		}
+		row[i] = a.apply(column(records, i), h.ColumnType(i))
+		if !a.counts() {
+			row[i] = h.formatField(i, row[i])
+		}
+	}
+	return row, nil
+}
+
+// footerRows computes the table's footer rows: the grand total row, if there
+// are subtotals, and the pivot's column totals, if there are any, followed by
+// the FooterRows.
+func (h *HTMLTable) footerRows() error {
+	h.footer = h.footer[:0]
+	if h.total != nil {
+		h.footer = append(h.footer, footRow{Class: "total", Cells: h.total})
+	}
+	if h.pivotTotal != nil {
+		row := make([]string, len(h.pivotTotal))
+		copy(row, h.pivotTotal)
+		// The first cell holds the label, unless there aren't any row keys.
+		for i := range row {
+			if !h.pivotAgg.counts() && (i > 0 || len(h.Pivot.Rows) == 0) {
+				row[i] = h.formatField(i, row[i])
+			}
+		}
+		h.footer = append(h.footer, footRow{Class: "total", Cells: row})
+	}
+	for _, fr := range h.FooterRows {
+		row, err := h.aggregateRow(fr.Aggregates, h.CSV, fr.Label)
+		if err != nil {
+			return err
+		}
+		h.footer = append(h.footer, footRow{Cells: row})
+	}
+	return nil
+}
+
+// footRows returns the table's computed footer rows.
+func (h *HTMLTable) footRows() []footRow {
+	return h.footer
+}
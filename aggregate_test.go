@@ -0,0 +1,116 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAggregateApply(t *testing.T) {
+	nums := []string{"1", "2.5", "", "-1,000", "2.5"}
+	dates := []string{"2016-09-14", "", "2015-01-31", "2016-10-01"}
+	text := []string{"file10", "file2", "", "file2"}
+	tests := []struct {
+		agg      Aggregate
+		values   []string
+		expected string
+	}{
+		{agg: AggNone, values: nums, expected: ""},
+		{agg: AggSum, values: nums, expected: "-994"},
+		{agg: AggCount, values: nums, expected: "4"},
+		{agg: AggMean, values: nums, expected: "-248.5"},
+		{agg: AggMedian, values: nums, expected: "1.75"},
+		{agg: AggMedian, values: []string{"3", "1", "2"}, expected: "2"},
+		{agg: AggMin, values: nums, expected: "-1,000"},
+		{agg: AggMax, values: nums, expected: "2.5"},
+		{agg: AggDistinct, values: nums, expected: "3"},
+		{agg: AggMin, values: dates, expected: "2015-01-31"},
+		{agg: AggMax, values: dates, expected: "2016-10-01"},
+		{agg: AggMax, values: text, expected: "file10"},
+		{agg: AggSum, values: text, expected: ""},
+	}
+	for i, test := range tests {
+		v := test.agg.Apply(test.values)
+		if v != test.expected {
+			t.Errorf("%d: %s: got %q; want %q", i, test.agg, v, test.expected)
+		}
+	}
+}
+
+func TestParseAggregates(t *testing.T) {
+	aggs, err := ParseAggregates("Revenue=sum, Orders=COUNT")
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	if len(aggs) != 2 || aggs["Revenue"] != AggSum || aggs["Orders"] != AggCount {
+		t.Errorf("got %v; want Revenue=sum, Orders=count", aggs)
+	}
+	_, err = ParseAggregates("Revenue=total")
+	if err == nil || err.Error() != `unknown aggregate: "total"` {
+		t.Errorf("got %v; want unknown aggregate error", err)
+	}
+}
+
+func TestFooterRows(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.Formats = map[string]Formatter{"Revenue": NumberFormat("$", 2)}
+	h.FooterRows = []FooterRow{
+		{Label: "Total", Aggregates: map[string]Aggregate{"Revenue": AggSum, "Rep": AggCount}},
+		{Label: "Average", Aggregates: map[string]Aggregate{"Revenue": AggMean}},
+	}
+	h.CSV = [][]string{
+		[]string{"Region", "Rep", "Revenue"},
+		[]string{"East", "Bob", "1000"},
+		[]string{"West", "Alice", "234.5"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	expected := `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th scope="col">Region</th>
+            <th scope="col">Rep</th>
+            <th scope="col">Revenue</th>
+        </tr>
+    </thead>
+    <tfoot>
+        <tr>
+            <td>Total</td>
+            <td>2</td>
+            <td>$1,234.50</td>
+        </tr>
+        <tr>
+            <td>Average</td>
+            <td></td>
+            <td>$617.25</td>
+        </tr>
+    </tfoot>
+    <tbody>
+        <tr>
+            <td>East</td>
+            <td>Bob</td>
+            <td>$1,000.00</td>
+        </tr>
+        <tr>
+            <td>West</td>
+            <td>Alice</td>
+            <td>$234.50</td>
+        </tr>
+    </tbody>
+</table>
+`
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+
+	h.Reset()
+	h.FooterRows = []FooterRow{{Aggregates: map[string]Aggregate{"Total": AggSum}}}
+	h.CSV = [][]string{[]string{"a"}, []string{"1"}}
+	err = h.Write(&buf)
+	if err == nil || !IsUnknownColumnErr(err) {
+		t.Errorf("got %v; want an unknown column error", err)
+	}
+}
@@ -0,0 +1,29 @@
+package csv2htmltable
+
+import (
+	"html/template"
+	"strings"
+)
+
+// attr is an HTML attribute.
+type attr struct {
+	name, value string
+}
+
+// attrs is a list of HTML attributes, which are written in order.
+type attrs []attr
+
+// HTMLAttr returns the attributes, each preceded by a space, with their
+// values escaped.  The attribute names are not escaped; they must be
+// constants.
+func (a attrs) HTMLAttr() template.HTMLAttr {
+	var b strings.Builder
+	for _, at := range a {
+		b.WriteByte(' ')
+		b.WriteString(at.name)
+		b.WriteString(`="`)
+		b.WriteString(template.HTMLEscapeString(at.value))
+		b.WriteByte('"')
+	}
+	return template.HTMLAttr(b.String())
+}
@@ -0,0 +1,166 @@
+package csv2htmltable
+
+import (
+	"html/template"
+	"strconv"
+)
+
+// bodyCell is a cell of a body row as it is rendered.
+type bodyCell struct {
+	Content template.HTML
+	Header  bool // if true, the cell is a th, otherwise it's a td
+	Attrs   template.HTMLAttr
+}
+
+// bodyRow is a body row as it is rendered.
+type bodyRow struct {
+	Attrs template.HTMLAttr
+	Cells []bodyCell
+}
+
+// placedCell is a body cell along with its position in its group, which is
+// needed to work out its header cells.
+type placedCell struct {
+	bodyCell
+	row, col   int    // the row and column the cell starts at
+	cols, rows int    // the number of columns and rows the cell spans
+	scope      string // the scope of a header cell; if empty, it's row
+	extra      attrs  // attributes that follow the generated ones
+}
+
+// bodyRows returns the rendered rows of a group.  Each field is formatted
+// using its column's Formatter and rendered using its ContentMode.  If
+// MergeColumns is set, the cells of the merged columns are row header cells
+// and the cells that are covered by a merged cell's rowspan are omitted.  If
+// the table's body is made of Rows, they are rendered instead.
+func (h *HTMLTable) bodyRows(g group) []bodyRow {
+	if len(h.Rows) > 0 {
+		cells, attrs := h.placeRows()
+		return h.finishRows(cells, attrs, g.Start)
+	}
+	cells, attrs := h.placeRecords(g.Rows)
+	return h.finishRows(cells, attrs, g.Start)
+}
+
+// placeRecords returns the cells of the records, along with the attributes
+// of each row.
+func (h *HTMLTable) placeRecords(records [][]string) ([][]placedCell, []template.HTMLAttr) {
+	spans := h.mergeSpans(records)
+	rows := make([][]placedCell, len(records))
+	trAttrs := make([]template.HTMLAttr, len(records))
+	for i, rec := range records {
+		rs, cs := h.applyRules(rec)
+		trAttrs[i] = rs.style(rowAttrs(nil, rs.class(h.rowClass(i, "")), "", nil)).HTMLAttr()
+		rows[i] = make([]placedCell, 0, len(rec))
+		for j := range rec {
+			c := placedCell{row: i, col: j, cols: 1, rows: 1}
+			c.Content = h.cellContent(rec, j)
+			c.Header = h.isRowHeader(j)
+			if spans != nil {
+				n, merged := spans[i][j]
+				if merged && n == 0 {
+					continue
+				}
+				if merged {
+					c.Header, c.rows = true, n
+				}
+			}
+			st := h.styleCell(rec, j, cs[j])
+			c.extra = st.style(rowAttrs(nil, st.class(h.cellClass(c.Header, "")), "", nil))
+			rows[i] = append(rows[i], c)
+		}
+	}
+	return rows, trAttrs
+}
+
+// finishRows returns the rendered rows of a group, whose first row is the
+// start'th body row, by working out the attributes of each cell.  Header
+// cells have a scope.  If the table's cells are associated with their headers
+// by ID, each header cell has an ID and each cell lists the IDs of its column
+// headers, followed by those of the row headers in its rows, in its headers
+// attribute; a row header cell only lists the row headers before it.
+func (h *HTMLTable) finishRows(cells [][]placedCell, trAttrs []template.HTMLAttr, start int) []bodyRow {
+	associate := h.associates()
+	// heads holds the ID of the row header cell that covers each slot.
+	var heads map[[2]int]string
+	if associate {
+		heads = make(map[[2]int]string)
+		for _, row := range cells {
+			for _, c := range row {
+				if !c.Header {
+					continue
+				}
+				id := h.rowHeaderID(start+c.row, c.col)
+				for r := c.row; r < c.row+c.rows; r++ {
+					for x := c.col; x < c.col+c.cols; x++ {
+						heads[[2]int{r, x}] = id
+					}
+				}
+			}
+		}
+	}
+	rows := make([]bodyRow, len(cells))
+	for i, row := range cells {
+		if i < len(trAttrs) {
+			rows[i].Attrs = trAttrs[i]
+		}
+		rows[i].Cells = make([]bodyCell, len(row))
+		for k, c := range row {
+			var a attrs
+			if c.cols > 1 {
+				a = append(a, attr{"colspan", strconv.Itoa(c.cols)})
+			}
+			if c.rows > 1 {
+				a = append(a, attr{"rowspan", strconv.Itoa(c.rows)})
+			}
+			if c.Header {
+				scope := c.scope
+				if scope == "" {
+					scope = "row"
+				}
+				a = append(a, attr{"scope", scope})
+				if associate {
+					a = append(a, attr{"id", h.rowHeaderID(start+c.row, c.col)})
+				}
+			}
+			if associate {
+				if ids := h.cellHeaders(c, heads); len(ids) > 0 {
+					a = append(a, attr{"headers", joinIDs(ids)})
+				}
+			}
+			a = append(a, c.extra...)
+			rows[i].Cells[k] = c.bodyCell
+			rows[i].Cells[k].Attrs = a.HTMLAttr()
+		}
+	}
+	return rows
+}
+
+// cellHeaders returns the IDs of the cell's header cells: the header cells of
+// its columns, followed by the row header cells of its rows.
+func (h *HTMLTable) cellHeaders(c placedCell, heads map[[2]int]string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for x := c.col; x < c.col+c.cols && x < len(h.colHeads); x++ {
+		for _, id := range h.colHeads[x] {
+			add(id)
+		}
+	}
+	for r := c.row; r < c.row+c.rows; r++ {
+		for x := 0; x < h.Cols; x++ {
+			if c.Header && x >= c.col {
+				break
+			}
+			if x < c.col || x >= c.col+c.cols {
+				add(heads[[2]int{r, x}])
+			}
+		}
+	}
+	return ids
+}
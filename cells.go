@@ -0,0 +1,280 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+var errCellOverlap = errors.New("cell spans overlap")
+var errRaggedRows = errors.New("rows are not rectangular")
+var errDataAttr = errors.New("invalid data attribute name")
+
+// Cell is a body cell with its own attributes.  A Cell can span multiple
+// columns and rows; the cells that it covers are omitted from the Rows that
+// follow, as in HTML.
+type Cell struct {
+	Text    string
+	Header  bool   // if true, the cell is a th, otherwise it's a td
+	Scope   string // the scope of a header cell; if empty, it's row
+	ColSpan int    // the number of columns the cell spans; 0 is the same as 1
+	RowSpan int    // the number of rows the cell spans; 0 is the same as 1
+	Class   string
+	Title   string // tooltip text
+	// data-* attributes, keyed by their name without the "data-" prefix,
+	// e.g. "id" for data-id.  Names may only contain lowercase ASCII
+	// letters, digits, '-', '_', and '.'.
+	Data map[string]string
+}
+
+// Row is a body row made of Cells, with its own attributes.
+type Row struct {
+	Cells []Cell
+	Class string
+	Title string
+	Data  map[string]string // data-* attributes, see Cell.Data
+}
+
+// RowsFromRecords returns the records as Rows, one Cell per field, so that
+// [][]string data can be used wherever Rows are.
+func RowsFromRecords(records [][]string) []Row {
+	rows := make([]Row, len(records))
+	for i, rec := range records {
+		rows[i].Cells = make([]Cell, len(rec))
+		for j, v := range rec {
+			rows[i].Cells[j].Text = v
+		}
+	}
+	return rows
+}
+
+// span returns the number of columns and rows that the cell spans.
+func (c Cell) span() (cols, rows int) {
+	cols, rows = c.ColSpan, c.RowSpan
+	if cols < 1 {
+		cols = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return cols, rows
+}
+
+// layoutRows places the rows' cells on the table's grid and returns the
+// column that each cell starts at, by row, along with the number of columns.
+// Cells are placed in the first column of their row that isn't covered by a
+// cell above them.  An error is returned if cells overlap, if the rows don't
+// all have the same number of columns, if a cell spans past the last row, or
+// if a data attribute name is invalid.  If there aren't any rows, there
+// aren't any columns.
+func layoutRows(rows []Row) ([][]int, int, error) {
+	if len(rows) == 0 {
+		return nil, 0, nil
+	}
+	for _, row := range rows {
+		err := validateData(row.Data)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, c := range row.Cells {
+			err = validateData(c.Data)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	cols := make([][]int, len(rows))
+	covered := make([]map[int]bool, len(rows))
+	for i := range covered {
+		covered[i] = make(map[int]bool)
+	}
+	for i, row := range rows {
+		cols[i] = make([]int, len(row.Cells))
+		var j int
+		for k, c := range row.Cells {
+			for covered[i][j] {
+				j++
+			}
+			cols[i][k] = j
+			w, n := c.span()
+			if i+n > len(rows) {
+				return nil, 0, fmt.Errorf("%s: row %d, cell %d spans past the last row", errRaggedRows, i, k)
+			}
+			for r := i; r < i+n; r++ {
+				for x := j; x < j+w; x++ {
+					if covered[r][x] {
+						return nil, 0, fmt.Errorf("%s: row %d, column %d", errCellOverlap, r, x)
+					}
+					covered[r][x] = true
+				}
+			}
+			j += w
+		}
+	}
+	width := len(covered[0])
+	for i := range covered {
+		if len(covered[i]) != width {
+			return nil, 0, fmt.Errorf("%s: row %d has %d columns; want %d", errRaggedRows, i, len(covered[i]), width)
+		}
+		for x := 0; x < width; x++ {
+			if !covered[i][x] {
+				return nil, 0, fmt.Errorf("%s: row %d, column %d is empty", errRaggedRows, i, x)
+			}
+		}
+	}
+	return cols, width, nil
+}
+
+// ValidateRows returns an error if the rows' cells can't be placed on a
+// rectangular grid without overlapping, or if a data attribute name is
+// invalid.
+func ValidateRows(rows []Row) error {
+	_, _, err := layoutRows(rows)
+	return err
+}
+
+func validateData(data map[string]string) error {
+	for k := range data {
+		if k == "" {
+			return fmt.Errorf("%s: %q", errDataAttr, k)
+		}
+		for _, r := range k {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' && r != '.' {
+				return fmt.Errorf("%s: %q", errDataAttr, k)
+			}
+		}
+	}
+	return nil
+}
+
+// processRows processes a table whose body is made of Rows.  The header rows
+// are the HeaderRows or, if those aren't set, the first HeaderRowNum records
+// of the CSV.  The Rows' text, by grid position, replaces the CSV records so
+// that the other output formats, column types, and aggregates use it; the
+// cells that are covered by a span are empty.  The transformations of the
+// records, e.g. Where and SortBy, don't apply to Rows, and Rows can't be
+// transposed.
+func (h *HTMLTable) processRows() error {
+	h.sparks = nil
+	if len(h.Sparklines) > 0 {
+		return fmt.Errorf("%s: Rows can't have sparklines", errSparkline)
+	}
+	if h.Transpose {
+		return fmt.Errorf("%s: Rows can't be transposed", errTranspose)
+	}
+	cols, width, err := layoutRows(h.Rows)
+	if err != nil {
+		return err
+	}
+	if len(h.HeaderRows) == 0 && h.HeaderRowNum > 0 {
+		if h.HeaderRowNum > len(h.CSV) {
+			return errTableHeader
+		}
+		h.HeaderRows = append(h.HeaderRows, h.CSV[:h.HeaderRowNum]...)
+	}
+	if h.HasHeader && len(h.HeaderRows) == 0 {
+		return errTableHeader
+	}
+	records := make([][]string, len(h.Rows))
+	for i := range records {
+		records[i] = make([]string, width)
+	}
+	for i, row := range h.Rows {
+		for k, c := range row.Cells {
+			records[i][cols[i][k]] = c.Text
+		}
+	}
+	h.CSV = records
+	h.Cols = width
+	h.names = nil
+	if len(h.HeaderRows) > 0 {
+		h.names = h.HeaderRows[0]
+	}
+	h.cellCols = cols
+	h.sortCol, h.sortDesc = -1, false
+	err = h.setFormats()
+	if err != nil {
+		return err
+	}
+	h.setTypes()
+	return nil
+}
+
+// placeRows returns the cells of the Rows, along with the attributes of each
+// row.  Each cell's text is formatted using the Formatter of the column that
+// it starts in.
+func (h *HTMLTable) placeRows() ([][]placedCell, []template.HTMLAttr) {
+	cells := make([][]placedCell, len(h.Rows))
+	trAttrs := make([]template.HTMLAttr, len(h.Rows))
+	for i, row := range h.Rows {
+		rec := h.rowRecord(i)
+		rs, cs := h.applyRules(rec)
+		trAttrs[i] = rs.style(rowAttrs(nil, rs.class(h.rowClass(i, row.Class)), row.Title, row.Data)).HTMLAttr()
+		cells[i] = make([]placedCell, len(row.Cells))
+		for k, c := range row.Cells {
+			w, n := c.span()
+			j := h.cellCols[i][k]
+			st := h.styleCell(rec, j, cs[j])
+			cells[i][k] = placedCell{
+				bodyCell: bodyCell{Content: h.cellContent(rec, j), Header: c.Header},
+				row:      i,
+				col:      j,
+				cols:     w,
+				rows:     n,
+				scope:    c.Scope,
+				extra:    st.style(rowAttrs(nil, st.class(h.cellClass(c.Header, c.Class)), c.Title, c.Data)),
+			}
+		}
+	}
+	return cells, trAttrs
+}
+
+// rowRecord returns the values of the i'th of the Rows, by column, e.g. for
+// links and rules.  Only the column that a cell starts at has its value.
+func (h *HTMLTable) rowRecord(i int) []string {
+	rec := make([]string, h.Cols)
+	for k, c := range h.Rows[i].Cells {
+		rec[h.cellCols[i][k]] = c.Text
+	}
+	return rec
+}
+
+// rowAttrs appends the class, title, and data-* attributes, in order of
+// name, to a.
+func rowAttrs(a attrs, class, title string, data map[string]string) attrs {
+	if class != "" {
+		a = append(a, attr{"class", class})
+	}
+	if title != "" {
+		a = append(a, attr{"title", title})
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		a = append(a, attr{"data-" + k, data[k]})
+	}
+	return a
+}
+
+// IsCellOverlapErr returns whether or not the error was a result of cells
+// whose spans overlap.
+func IsCellOverlapErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errCellOverlap.Error())
+}
+
+// IsRaggedRowsErr returns whether or not the error was a result of Rows that
+// don't make a rectangular grid.
+func IsRaggedRowsErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errRaggedRows.Error())
+}
+
+// IsDataAttrErr returns whether or not the error was a result of an invalid
+// data attribute name.
+func IsDataAttrErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errDataAttr.Error())
+}
@@ -0,0 +1,156 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	tests := []struct {
+		Rows        []Row
+		Expected    string
+		ExpectedErr string
+	}{
+		{ // 0
+			Rows: RowsFromRecords([][]string{
+				[]string{"Bob", "10"},
+				[]string{"Genvieve", "9"},
+			}),
+			Expected: `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th scope="col">Name</th>
+            <th scope="col">Score</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr>
+            <td>Bob</td>
+            <td>10</td>
+        </tr>
+        <tr>
+            <td>Genvieve</td>
+            <td>9</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 1
+			Rows: []Row{
+				{Class: "first", Data: map[string]string{"id": "7", "group": "a"}, Cells: []Cell{
+					{Text: "Bob", Header: true, RowSpan: 2, Title: "Robert"},
+					{Text: "10", Class: "high"},
+				}},
+				{Cells: []Cell{
+					{Text: "9"},
+				}},
+				{Cells: []Cell{
+					{Text: "n/a", ColSpan: 2},
+				}},
+			},
+			Expected: `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th scope="col">Name</th>
+            <th scope="col">Score</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr class="first" data-group="a" data-id="7">
+            <th rowspan="2" scope="row" title="Robert">Bob</th>
+            <td class="high">10</td>
+        </tr>
+        <tr>
+            <td>9</td>
+        </tr>
+        <tr>
+            <td colspan="2">n/a</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 2
+			Rows: []Row{
+				{Cells: []Cell{{Text: "a"}, {Text: "b", RowSpan: 2}}},
+				{Cells: []Cell{{Text: "c", ColSpan: 2}}},
+			},
+			ExpectedErr: "cell spans overlap: row 1, column 1",
+		},
+		{ // 3
+			Rows: []Row{
+				{Cells: []Cell{{Text: "a"}, {Text: "b"}}},
+				{Cells: []Cell{{Text: "c"}}},
+			},
+			ExpectedErr: "rows are not rectangular: row 1 has 1 columns; want 2",
+		},
+		{ // 4
+			Rows: []Row{
+				{Cells: []Cell{{Text: "a", RowSpan: 3}, {Text: "b"}}},
+				{Cells: []Cell{{Text: "c"}}},
+			},
+			ExpectedErr: "rows are not rectangular: row 0, cell 0 spans past the last row",
+		},
+		{ // 5
+			Rows: []Row{
+				{Cells: []Cell{{Text: "a", Data: map[string]string{`x" onclick="y`: "z"}}, {Text: "b"}}},
+			},
+			ExpectedErr: `invalid data attribute name: "x\" onclick=\"y"`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.HeaderRowNum = 0
+		h.HeaderRows = [][]string{[]string{"Name", "Score"}}
+		h.Rows = test.Rows
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil: want %q", i, test.ExpectedErr)
+			continue
+		}
+		if buf.String() != test.Expected {
+			t.Errorf("%d got %q; want %q", i, buf.String(), test.Expected)
+		}
+	}
+}
+
+func TestRowsErrs(t *testing.T) {
+	err := ValidateRows([]Row{
+		{Cells: []Cell{{Text: "a", ColSpan: 2, RowSpan: 2}}},
+		{Cells: []Cell{{Text: "b"}}},
+	})
+	if err == nil || !IsRaggedRowsErr(err) {
+		t.Errorf("got %v; want a rows are not rectangular error", err)
+	}
+	err = ValidateRows([]Row{{Data: map[string]string{"ID": "1"}, Cells: []Cell{{Text: "a"}}}})
+	if err == nil || !IsDataAttrErr(err) {
+		t.Errorf("got %v; want an invalid data attribute name error", err)
+	}
+	err = ValidateRows([]Row{
+		{Cells: []Cell{{Text: "a"}, {Text: "b", RowSpan: 2}}},
+		{Cells: []Cell{{Text: "c", ColSpan: 2}}},
+	})
+	if err == nil || !IsCellOverlapErr(err) {
+		t.Errorf("got %v; want a cell spans overlap error", err)
+	}
+	for _, rows := range [][]Row{nil, []Row{}} {
+		err = ValidateRows(rows)
+		if err != nil {
+			t.Errorf("got %v; want nil for empty rows", err)
+		}
+	}
+}
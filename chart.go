@@ -0,0 +1,431 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errChart = errors.New("invalid chart")
+
+// ChartType is the type of a Chart.
+type ChartType int
+
+// Supported chart types.
+const (
+	ChartBar ChartType = iota
+	ChartLine
+	ChartStackedBar
+)
+
+var chartTypeNames = [...]string{"bar", "line", "stacked"}
+
+func (c ChartType) String() string {
+	if c < 0 || int(c) >= len(chartTypeNames) {
+		return fmt.Sprintf("ChartType(%d)", int(c))
+	}
+	return chartTypeNames[c]
+}
+
+// ParseChartType returns the ChartType with the received name: "bar",
+// "line", or "stacked".
+func ParseChartType(s string) (ChartType, error) {
+	for i, name := range chartTypeNames {
+		if strings.EqualFold(name, s) {
+			return ChartType(i), nil
+		}
+	}
+	return 0, fmt.Errorf("%s: unknown chart type %q", errChart, s)
+}
+
+// Chart is an SVG chart of the table's body: each record is a category,
+// labelled by its Category column's value, and each Values column is a
+// series.  Values that aren't numbers are gaps in a line and don't have a
+// bar.  The chart is an img for assistive technology, with a title and a
+// description, and links to the table that holds its data, so that its values
+// can be read.
+type Chart struct {
+	// The type of chart.
+	Type ChartType
+	// The key of the column whose values label the categories.
+	Category string
+	// The keys of the columns whose values are plotted; if nil, every numeric
+	// column other than Category is plotted.
+	Values []string
+	// The chart's title; if empty, the text of the table's caption or
+	// heading is used, e.g. Caption or CaptionHTML without its tags.
+	Title string
+	// The chart's dimensions, in pixels; if 0, 600 by 300 is used.
+	Width, Height int
+	// The series' colors, in order; if nil, the DefaultChartColors are used.
+	Colors []string
+	// The URL of the table that holds the chart's data.  When the chart is
+	// written with the table, it defaults to the table, by its ID.  A
+	// standalone chart only links to its table if this is set.
+	TableURL string
+}
+
+// DefaultChartColors are the colors of a chart's series.
+var DefaultChartColors = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+// The default chart dimensions and the margins around its plot.
+const (
+	chartWidth  = 600
+	chartHeight = 300
+	chartLeft   = 48
+	chartRight  = 12
+	chartTop    = 32
+	chartBottom = 36
+	chartLegend = 20 // the height of the legend, if there is one
+)
+
+// WriteChart writes the table's Chart to the received io.Writer as a
+// standalone SVG document.
+func (h *HTMLTable) WriteChart(w io.Writer) error {
+	if h.Chart == nil {
+		return fmt.Errorf("%s: the table doesn't have a chart", errChart)
+	}
+	err := h.process()
+	if err != nil {
+		return err
+	}
+	svg, err := h.chartSVG(h.Chart.TableURL)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"+svg+"\n")
+	return err
+}
+
+// setChart renders the Chart that is written with the table, followed by a
+// link to the table; if the table doesn't have a Chart, it's cleared.
+func (h *HTMLTable) setChart() error {
+	h.chart = ""
+	if h.Chart == nil {
+		return nil
+	}
+	url := h.Chart.TableURL
+	if url == "" {
+		if h.ID == "" {
+			return fmt.Errorf("%s: a table ID or TableURL is required to link the chart to its data", errChart)
+		}
+		url = "#" + h.ID
+	}
+	svg, err := h.chartSVG("")
+	if err != nil {
+		return err
+	}
+	h.chart = template.HTML(svg + "\n" + `<p class="chart-data"><a href="` + template.HTMLEscapeString(url) + `">Data table for this chart</a></p>`)
+	return nil
+}
+
+// chartHTML returns the rendered Chart, if the table has one.
+func (h *HTMLTable) chartHTML() template.HTML {
+	return h.chart
+}
+
+// chartData is a Chart resolved against the table's body.
+type chartData struct {
+	categories []string
+	series     []string    // the name of each series
+	values     [][]float64 // the values of each series, by category
+}
+
+// chartData resolves the Chart's columns and collects its values.
+func (h *HTMLTable) chartData(c *Chart) (*chartData, error) {
+	cat := h.columnIndex(c.Category)
+	if cat < 0 {
+		return nil, unknownColumnErr(c.Category)
+	}
+	var cols []int
+	for _, k := range c.Values {
+		i := h.columnIndex(k)
+		if i < 0 {
+			return nil, unknownColumnErr(k)
+		}
+		cols = append(cols, i)
+	}
+	if c.Values == nil {
+		for i, t := range h.types {
+			if i != cat && t == TypeNumeric {
+				cols = append(cols, i)
+			}
+		}
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("%s: there aren't any value columns", errChart)
+	}
+	d := &chartData{}
+	for _, i := range cols {
+		name := strconv.Itoa(i)
+		if i < len(h.names) {
+			name = h.names[i]
+		}
+		d.series = append(d.series, name)
+	}
+	d.values = make([][]float64, len(cols))
+	for _, rec := range h.bodyRecords() {
+		d.categories = append(d.categories, field(rec, cat))
+		for s, i := range cols {
+			f, ok := ParseNumber(field(rec, i))
+			if !ok {
+				f = math.NaN()
+			}
+			d.values[s] = append(d.values[s], f)
+		}
+	}
+	return d, nil
+}
+
+// chartSVG returns the Chart as an svg element.  If url isn't empty, the
+// chart includes a link to its data table at that URL.
+func (h *HTMLTable) chartSVG(url string) (string, error) {
+	c := h.Chart
+	d, err := h.chartData(c)
+	if err != nil {
+		return "", err
+	}
+	colors := c.Colors
+	if len(colors) == 0 {
+		colors = DefaultChartColors
+	}
+	w, ht := c.Width, c.Height
+	if w <= 0 {
+		w = chartWidth
+	}
+	if ht <= 0 {
+		ht = chartHeight
+	}
+	title := c.Title
+	if title == "" {
+		title = h.captionText()
+	}
+	if title == "" {
+		title = h.headingText()
+	}
+	id := h.idPrefix() + "-chart"
+	p := plot{x0: chartLeft, x1: float64(w - chartRight), y0: chartTop, y1: float64(ht - chartBottom)}
+	if len(d.series) > 1 {
+		p.y0 += chartLegend
+	}
+	if url != "" {
+		p.y1 -= 16
+	}
+	p.lo, p.hi = d.extent(c.Type == ChartStackedBar)
+	p.step = niceStep(p.hi-p.lo, 5)
+	p.lo = math.Floor(p.lo/p.step) * p.step
+	p.hi = math.Ceil(p.hi/p.step) * p.step
+
+	labels := id + "-desc"
+	if title != "" {
+		labels = id + "-title " + labels
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" class="chart chart-%s" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-labelledby="%s" font-family="sans-serif" font-size="11">`,
+		c.Type, w, ht, w, ht, labels)
+	if title != "" {
+		fmt.Fprintf(&b, "\n"+`<title id="%s-title">%s</title>`, id, esc(title))
+	}
+	fmt.Fprintf(&b, "\n"+`<desc id="%s-desc">%s</desc>`, id, esc(d.describe(c.Type)))
+	if title != "" {
+		fmt.Fprintf(&b, "\n"+`<text x="%s" y="18" text-anchor="middle" font-size="14" font-weight="bold">%s</text>`, svgNum(float64(w)/2), esc(title))
+	}
+	if len(d.series) > 1 {
+		x := float64(chartLeft)
+		for s, name := range d.series {
+			fmt.Fprintf(&b, "\n"+`<rect x="%s" y="%d" width="10" height="10" fill="%s"></rect><text x="%s" y="%d">%s</text>`,
+				svgNum(x), chartTop-2, esc(colors[s%len(colors)]), svgNum(x+14), chartTop+7, esc(name))
+			x += 24 + 6.5*float64(len([]rune(name)))
+		}
+	}
+	p.axes(&b, d.categories)
+	switch c.Type {
+	case ChartLine:
+		p.lines(&b, d, colors)
+	case ChartStackedBar:
+		p.stackedBars(&b, d, colors)
+	default:
+		p.bars(&b, d, colors)
+	}
+	if url != "" {
+		fmt.Fprintf(&b, "\n"+`<a href="%s"><text x="%d" y="%d">Data table for this chart</text></a>`, esc(url), chartLeft, ht-6)
+	}
+	b.WriteString("\n</svg>")
+	return b.String(), nil
+}
+
+func esc(s string) string {
+	return template.HTMLEscapeString(s)
+}
+
+// describe returns a description of the chart, e.g. "Bar chart of Revenue
+// and Cost by Region, for 4 categories."
+func (d *chartData) describe(t ChartType) string {
+	kind := map[ChartType]string{ChartBar: "Bar", ChartLine: "Line", ChartStackedBar: "Stacked bar"}[t]
+	series := strings.Join(d.series, ", ")
+	if n := len(d.series); n > 1 {
+		series = strings.Join(d.series[:n-1], ", ") + " and " + d.series[n-1]
+	}
+	return fmt.Sprintf("%s chart of %s, for %d categories.", kind, series, len(d.categories))
+}
+
+// extent returns the range of the values, including zero.  If stacked is
+// true, the range is that of the sums of each category's positive values and
+// of its negative values.
+func (d *chartData) extent(stacked bool) (lo, hi float64) {
+	for i := range d.categories {
+		var pos, neg float64
+		for s := range d.series {
+			v := d.values[s][i]
+			if math.IsNaN(v) {
+				continue
+			}
+			if !stacked {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+				continue
+			}
+			if v > 0 {
+				pos += v
+			} else {
+				neg += v
+			}
+		}
+		lo, hi = math.Min(lo, neg), math.Max(hi, pos)
+	}
+	if lo == hi {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+// niceStep returns a step of 1, 2, or 5 times a power of 10 that divides the
+// span into about n intervals.
+func niceStep(span float64, n int) float64 {
+	raw := span / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*mag {
+			return m * mag
+		}
+	}
+	return 10 * mag
+}
+
+// plot is the area of a chart that its values are plotted in, along with
+// the range of its y axis.
+type plot struct {
+	x0, x1, y0, y1 float64
+	lo, hi, step   float64
+}
+
+func (p *plot) y(v float64) float64 {
+	return p.y1 - (v-p.lo)/(p.hi-p.lo)*(p.y1-p.y0)
+}
+
+// band returns the width of each category's band.
+func (p *plot) band(n int) float64 {
+	return (p.x1 - p.x0) / float64(n)
+}
+
+// axes writes the y axis' gridlines and labels, the zero line, and the
+// category labels.
+func (p *plot) axes(b *strings.Builder, categories []string) {
+	for t := p.lo; t <= p.hi+p.step/2; t += p.step {
+		y := svgNum(p.y(t))
+		fmt.Fprintf(b, "\n"+`<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#dddddd"></line><text x="%s" y="%s" text-anchor="end">%s</text>`,
+			svgNum(p.x0), y, svgNum(p.x1), y, svgNum(p.x0-4), svgNum(p.y(t)+4), formatFloat(math.Round(t/p.step)*p.step))
+	}
+	y := svgNum(p.y(0))
+	fmt.Fprintf(b, "\n"+`<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#333333"></line>`, svgNum(p.x0), y, svgNum(p.x1), y)
+	band := p.band(len(categories))
+	for i, c := range categories {
+		fmt.Fprintf(b, "\n"+`<text x="%s" y="%s" text-anchor="middle">%s</text>`, svgNum(p.x0+band*(float64(i)+0.5)), svgNum(p.y1+16), esc(c))
+	}
+}
+
+// bars writes each category's values as bars side by side.
+func (p *plot) bars(b *strings.Builder, d *chartData, colors []string) {
+	band := p.band(len(d.categories))
+	w := band * 0.8 / float64(len(d.series))
+	for i, c := range d.categories {
+		for s, name := range d.series {
+			v := d.values[s][i]
+			if math.IsNaN(v) {
+				continue
+			}
+			p.bar(b, p.x0+band*(float64(i)+0.1)+w*float64(s), w, 0, v, colors[s%len(colors)], c, name, v)
+		}
+	}
+}
+
+// stackedBars writes each category's values as a stack; positive values are
+// stacked up from zero and negative values down from it.
+func (p *plot) stackedBars(b *strings.Builder, d *chartData, colors []string) {
+	band := p.band(len(d.categories))
+	for i, c := range d.categories {
+		var pos, neg float64
+		for s, name := range d.series {
+			v := d.values[s][i]
+			if math.IsNaN(v) {
+				continue
+			}
+			base := &pos
+			if v < 0 {
+				base = &neg
+			}
+			p.bar(b, p.x0+band*(float64(i)+0.2), band*0.6, *base, *base+v, colors[s%len(colors)], c, name, v)
+			*base += v
+		}
+	}
+}
+
+// bar writes a bar from one value to another with a title that gives its
+// category, series, and value.
+func (p *plot) bar(b *strings.Builder, x, w, from, to float64, color, category, series string, v float64) {
+	top, bottom := math.Min(p.y(from), p.y(to)), math.Max(p.y(from), p.y(to))
+	fmt.Fprintf(b, "\n"+`<rect x="%s" y="%s" width="%s" height="%s" fill="%s"><title>%s, %s: %s</title></rect>`,
+		svgNum(x), svgNum(top), svgNum(w), svgNum(bottom-top), esc(color), esc(category), esc(series), formatFloat(v))
+}
+
+// lines writes each series as a line, with a point at each value.
+func (p *plot) lines(b *strings.Builder, d *chartData, colors []string) {
+	band := p.band(len(d.categories))
+	x := func(i int) float64 { return p.x0 + band*(float64(i)+0.5) }
+	for s, name := range d.series {
+		color := esc(colors[s%len(colors)])
+		var path []string
+		move := true
+		for i, v := range d.values[s] {
+			if math.IsNaN(v) {
+				move = true
+				continue
+			}
+			cmd := "L"
+			if move {
+				cmd, move = "M", false
+			}
+			path = append(path, cmd+svgNum(x(i))+" "+svgNum(p.y(v)))
+		}
+		fmt.Fprintf(b, "\n"+`<path d="%s" fill="none" stroke="%s" stroke-width="2"></path>`, strings.Join(path, " "), color)
+		for i, v := range d.values[s] {
+			if math.IsNaN(v) {
+				continue
+			}
+			fmt.Fprintf(b, "\n"+`<circle cx="%s" cy="%s" r="3" fill="%s"><title>%s, %s: %s</title></circle>`,
+				svgNum(x(i)), svgNum(p.y(v)), color, esc(d.categories[i]), esc(name), formatFloat(v))
+		}
+	}
+}
+
+// IsChartErr returns whether or not the error was a result of an invalid
+// Chart.
+func IsChartErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errChart.Error())
+}
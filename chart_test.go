@@ -0,0 +1,203 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html/template"
+	"io"
+	"strings"
+	"testing"
+)
+
+func chartTable() *HTMLTable {
+	h := New("sales")
+	h.Caption = "Sales by region"
+	h.CSV = [][]string{
+		[]string{"Region", "Revenue", "Cost", "Notes"},
+		[]string{"North", "120", "80", "a"},
+		[]string{"South", "90", "100", "b"},
+		[]string{"East", "", "-20", "c"},
+	}
+	return h
+}
+
+func TestChart(t *testing.T) {
+	tests := []struct {
+		Chart       Chart
+		ID          string
+		CaptionHTML template.HTML
+		Expected    []string
+		NotExpected []string
+		ExpectedErr string
+	}{
+		{ // 0
+			Chart: Chart{Category: "Region"},
+			ID:    "sales",
+			Expected: []string{
+				`<svg xmlns="http://www.w3.org/2000/svg" class="chart chart-bar" width="600" height="300" viewBox="0 0 600 300" role="img" aria-labelledby="sales-chart-title sales-chart-desc"`,
+				`<title id="sales-chart-title">Sales by region</title>`,
+				`<desc id="sales-chart-desc">Bar chart of Revenue and Cost, for 3 categories.</desc>`,
+				`<title>North, Revenue: 120</title>`,
+				`<title>East, Cost: -20</title>`,
+				`<text x="138" y="280" text-anchor="middle">North</text>`,
+				"</svg>\n" + `<p class="chart-data"><a href="#sales">Data table for this chart</a></p>` + "\n<table",
+			},
+			NotExpected: []string{"East, Revenue", ">Notes</text>"},
+		},
+		{ // 1
+			Chart: Chart{Type: ChartLine, Category: "Region", Values: []string{"Cost"}, Title: "Cost", TableURL: "/sales"},
+			Expected: []string{
+				`class="chart chart-line"`,
+				`<desc id="sales-chart-desc">Line chart of Cost, for 3 categories.</desc>`,
+				`<path d="M138 62.9 L318 32 L498 217.6"`,
+				`<circle cx="498" cy="217.6" r="3" fill="#4e79a7"><title>East, Cost: -20</title></circle>`,
+				`<a href="/sales">Data table for this chart</a>`,
+			},
+			NotExpected: []string{`<rect x="48" y="30" width="10"`},
+		},
+		{ // 2
+			Chart: Chart{Type: ChartStackedBar, Category: "Region", Colors: []string{"#111", "#222"}},
+			ID:    "sales",
+			Expected: []string{
+				`Stacked bar chart of Revenue and Cost`,
+				`fill="#111"><title>North, Revenue: 120</title>`,
+				`fill="#222"><title>North, Cost: 80</title>`,
+				`<text x="62" y="39">Revenue</text>`,
+			},
+		},
+		{ // 3
+			Chart:       Chart{Category: "Region"},
+			ExpectedErr: "invalid chart: a table ID or TableURL is required to link the chart to its data",
+		},
+		{ // 4
+			Chart:       Chart{Category: "Month"},
+			ID:          "sales",
+			ExpectedErr: `unknown column: "Month"`,
+		},
+		{ // 5
+			Chart:       Chart{Category: "Region", Values: []string{}},
+			ID:          "sales",
+			ExpectedErr: "invalid chart: there aren't any value columns",
+		},
+		{ // 6
+			Chart:       Chart{Category: "Region"},
+			ID:          "sales",
+			CaptionHTML: "<em>Sales</em> &amp; costs",
+			Expected:    []string{`<title id="sales-chart-title">Sales &amp; costs</title>`},
+		},
+	}
+	var buf bytes.Buffer
+	for i, test := range tests {
+		buf.Reset()
+		h := chartTable()
+		h.ID = test.ID
+		h.CaptionHTML = test.CaptionHTML
+		c := test.Chart
+		h.Chart = &c
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q; want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		for _, v := range test.Expected {
+			if !strings.Contains(buf.String(), v) {
+				t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), v)
+			}
+		}
+		for _, v := range test.NotExpected {
+			if strings.Contains(buf.String(), v) {
+				t.Errorf("%d: got %q; want it to not contain %q", i, buf.String(), v)
+			}
+		}
+	}
+}
+
+func TestWriteChart(t *testing.T) {
+	var buf bytes.Buffer
+	h := chartTable()
+	h.Chart = &Chart{Category: "Region", Values: []string{"Revenue"}, Title: `Q3 <"draft">`}
+	err := h.WriteChart(&buf)
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+	if !strings.HasPrefix(buf.String(), `<?xml version="1.0" encoding="UTF-8"?>`+"\n<svg ") {
+		t.Errorf("got %q; want a standalone SVG document", buf.String())
+	}
+	if strings.Contains(buf.String(), "<a ") {
+		t.Errorf("got %q; want no data table link", buf.String())
+	}
+	// The document must be well-formed XML.
+	dec := xml.NewDecoder(strings.NewReader(buf.String()))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("got %q; want well-formed XML", err)
+		}
+	}
+	buf.Reset()
+	h = chartTable()
+	h.Caption = ""
+	h.Chart = &Chart{Category: "Region", TableURL: "sales.html"}
+	err = h.WriteChart(&buf)
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+	for _, want := range []string{`aria-labelledby="sales-chart-desc"`, `<a href="sales.html"><text x="48" y="294">Data table for this chart</text></a>`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("got %q; want it to contain %q", buf.String(), want)
+		}
+	}
+	if strings.Contains(buf.String(), "<title id=") {
+		t.Errorf("got %q; want no title", buf.String())
+	}
+	h = chartTable()
+	err = h.WriteChart(&buf)
+	if err == nil || !IsChartErr(err) {
+		t.Errorf("no chart: got %v; want a chart error", err)
+	}
+}
+
+func TestParseChartType(t *testing.T) {
+	for i, name := range []string{"bar", "Line", "STACKED"} {
+		c, err := ParseChartType(name)
+		if err != nil {
+			t.Errorf("%d: got %q; want nil", i, err)
+			continue
+		}
+		if c != ChartType(i) {
+			t.Errorf("%d: got %s; want %s", i, c, ChartType(i))
+		}
+	}
+	_, err := ParseChartType("pie")
+	if err == nil || err.Error() != `invalid chart: unknown chart type "pie"` {
+		t.Errorf("got %v; want an unknown chart type error", err)
+	}
+}
+
+func TestNiceStep(t *testing.T) {
+	tests := []struct {
+		span     float64
+		expected float64
+	}{
+		{span: 140, expected: 50},
+		{span: 10, expected: 2},
+		{span: 1, expected: 0.2},
+		{span: 900, expected: 200},
+	}
+	for i, test := range tests {
+		if got := niceStep(test.span, 5); got != test.expected {
+			t.Errorf("%d: got %v; want %v", i, got, test.expected)
+		}
+	}
+}
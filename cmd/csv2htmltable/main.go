@@ -4,7 +4,9 @@ import (
 	"encoding/csv"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/mohae/csv2htmltable"
 )
@@ -24,6 +26,55 @@ var (
 	headerRowNum int
 	rowHeader    bool
 	footer       string
+
+	sortable      bool
+	filterable    bool
+	columnFilters bool
+	nonce         string
+
+	pageSize int
+	pageDir  string
+
+	columns string
+	rename  string
+	where   string
+	sortBy  string
+
+	groupBy   string
+	subtotals string
+	totals    string
+
+	pivotRows   string
+	pivotCols   string
+	pivotValue  string
+	pivotAgg    string
+	pivotTotals bool
+	spanHeaders bool
+	transpose   bool
+	merge       string
+	colClasses  string
+	headerIDs   bool
+	description string
+	content     string
+	links       string
+	linkRel     string
+	linkTarget  string
+	images      string
+	badges      string
+	icons       string
+	rules       string
+	heatmap     string
+	dataBars    string
+	sparkRange  string
+	sparkBars   bool
+
+	chartType     string
+	chartCategory string
+	chartValues   string
+	chartSVG      bool
+
+	theme      string
+	includeCSS bool
 )
 
 func init() {
@@ -54,6 +105,51 @@ func init() {
 	flag.IntVar(&headerRowNum, "n", 1, "number of header rows in the csv")
 	flag.BoolVar(&rowHeader, "rowheader", false, "make the first column of each row a header")
 	flag.BoolVar(&rowHeader, "r", false, "make the first column of each row a header")
+
+	flag.BoolVar(&sortable, "sortable", false, "include a script that sorts the table by a column when its header is clicked")
+	flag.BoolVar(&filterable, "filter", false, "include a search input that filters the table's rows; requires -id")
+	flag.BoolVar(&columnFilters, "columnfilters", false, "include a filter input for each column; requires -id")
+	flag.StringVar(&nonce, "nonce", "", "CSP nonce for the inline scripts and stylesheet")
+
+	flag.IntVar(&pageSize, "pagesize", 0, "split the table into pages of this many rows; 0 disables pagination")
+	flag.StringVar(&pageDir, "pagedir", ".", "the directory the pages are written to when paginating")
+
+	flag.StringVar(&columns, "columns", "", "comma separated list of the columns to output, in order, by header or 0-based index")
+	flag.StringVar(&rename, "rename", "", "comma separated list of old=new header renames")
+	flag.StringVar(&sortBy, "sort", "", "comma separated list of the columns to sort by; prefix a column with - to sort it in descending order")
+	flag.StringVar(&groupBy, "groupby", "", "comma separated list of the columns to group the rows by")
+	flag.StringVar(&subtotals, "subtotals", "", "comma separated list of column=aggregate pairs for group subtotals, e.g. Revenue=sum")
+	flag.StringVar(&totals, "totals", "", "comma separated list of column=aggregate pairs for a footer totals row; aggregates are sum, mean, median, min, max, count, and distinct")
+	flag.StringVar(&pivotRows, "pivotrows", "", "comma separated list of the columns whose values become the rows of a pivot table")
+	flag.StringVar(&pivotCols, "pivotcols", "", "comma separated list of the columns whose values become the columns of a pivot table")
+	flag.StringVar(&pivotValue, "pivotvalue", "", "the column whose values are aggregated in a pivot table; setting it enables the pivot")
+	flag.StringVar(&pivotAgg, "pivotagg", "sum", "the aggregate for the cells of a pivot table")
+	flag.BoolVar(&pivotTotals, "pivottotals", false, "add row and column totals to a pivot table")
+	flag.BoolVar(&spanHeaders, "spanheaders", false, "merge repeated and empty header cells using colspan and rowspan")
+	flag.BoolVar(&transpose, "transpose", false, "flip the table's rows and columns; the header rows become row header columns")
+	flag.StringVar(&merge, "merge", "", "comma separated list of the columns whose consecutive identical values are merged using rowspan")
+	flag.StringVar(&colClasses, "colclasses", "", "add a colgroup whose col elements have classes derived from each column's type, name, or both, e.g. type,name")
+	flag.BoolVar(&headerIDs, "headerids", false, "give every header cell an id and list each cell's header cells in its headers attribute")
+	flag.StringVar(&content, "content", "", "comma separated list of column=mode pairs for cells with rich content; modes are text, markdown, html, which is sanitized, and autolink")
+	flag.StringVar(&links, "links", "", "comma separated list of column=URL template pairs that make the column's cells links, e.g. ID=https://tracker/issue/{ID}")
+	flag.StringVar(&linkRel, "linkrel", "", "the rel attribute of links, e.g. nofollow")
+	flag.StringVar(&linkTarget, "linktarget", "", "the target attribute of links, e.g. _blank")
+	flag.StringVar(&images, "images", "", "comma separated list of the columns of image URLs to render as images; use column=altcolumn to take the alt text from another column")
+	flag.StringVar(&badges, "badges", "", "comma separated list of the columns to render as badges, with a class derived from each value, e.g. badge-in-stock")
+	flag.StringVar(&icons, "icons", "", "comma separated list of the columns of booleans to render as check and cross icons")
+	flag.StringVar(&rules, "rules", "", "the path to a JSON file of conditional formatting rules, e.g. [{\"Column\": \"Balance\", \"When\": \"Balance < 0\", \"Class\": \"negative\"}]")
+	flag.StringVar(&heatmap, "heatmap", "", "comma separated list of the numeric columns to shade with a color scale, each optionally with its colors, e.g. Score,Load=#ffffff:#ff0000")
+	flag.StringVar(&dataBars, "databars", "", "comma separated list of the numeric columns to draw data bars in")
+	flag.StringVar(&sparkRange, "sparkline", "", "add a column of sparklines of the range of columns from:to, optionally preceded by the column's header and =, e.g. Trend=W1:W8")
+	flag.BoolVar(&sparkBars, "sparkbars", false, "draw the sparklines as bar charts instead of line charts")
+	flag.StringVar(&chartType, "chart", "", "write a chart of the table's data before the table: bar, line, or stacked; requires -chartcategory and -id")
+	flag.StringVar(&chartCategory, "chartcategory", "", "the column whose values label the chart's categories")
+	flag.StringVar(&chartValues, "chartvalues", "", "comma separated list of the columns to chart; if not specified, all of the numeric columns are charted")
+	flag.BoolVar(&chartSVG, "chartsvg", false, "write the chart as a standalone SVG document instead of the table")
+	flag.StringVar(&theme, "theme", "", "add the classes of a built-in theme to the table's parts: bare, striped, bootstrap, bulma, or tailwind")
+	flag.BoolVar(&includeCSS, "css", false, "inline the theme's stylesheet before the table; only the bare and striped themes have one")
+	flag.StringVar(&description, "description", "", "a description of the table, referenced by its aria-describedby attribute")
+	flag.StringVar(&where, "where", "", "only output the rows that match this filter expression, e.g. 'Status == \"open\" && Amount > 100'")
 }
 
 func main() {
@@ -61,7 +157,14 @@ func main() {
 }
 
 func realMain() int {
-	flag.Parse()
+	// The lint command checks the table, configured by the same flags, for
+	// accessibility problems instead of writing it.
+	args := os.Args[1:]
+	lint := len(args) > 0 && args[0] == "lint"
+	if lint {
+		args = args[1:]
+	}
+	flag.CommandLine.Parse(args)
 
 	var err error
 	// by default set to stdin and stdout
@@ -109,16 +212,265 @@ func realMain() int {
 	htable.HeadingTag = headingTag
 	htable.HasHeader = tableHeader
 	htable.HeaderRowNum = headerRowNum
+	htable.Columns, err = csv2htmltable.ParseColumns(columns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing columns: %s\n", err)
+		return 1
+	}
+	htable.Rename, err = csv2htmltable.ParseRename(rename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing rename: %s\n", err)
+		return 1
+	}
+	htable.Where = where
+	htable.SortBy, err = csv2htmltable.ParseSort(sortBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing sort: %s\n", err)
+		return 1
+	}
+	htable.GroupBy, err = csv2htmltable.ParseColumns(groupBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing groupby: %s\n", err)
+		return 1
+	}
+	htable.Subtotals, err = csv2htmltable.ParseAggregates(subtotals)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing subtotals: %s\n", err)
+		return 1
+	}
+	if totals != "" {
+		aggs, err := csv2htmltable.ParseAggregates(totals)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing totals: %s\n", err)
+			return 1
+		}
+		htable.FooterRows = []csv2htmltable.FooterRow{{Label: csv2htmltable.TotalLabel, Aggregates: aggs}}
+	}
+	if pivotValue != "" {
+		p := csv2htmltable.Pivot{Value: pivotValue, RowTotals: pivotTotals, ColumnTotals: pivotTotals}
+		p.Rows, err = csv2htmltable.ParseColumns(pivotRows)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing pivotrows: %s\n", err)
+			return 1
+		}
+		p.Columns, err = csv2htmltable.ParseColumns(pivotCols)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing pivotcols: %s\n", err)
+			return 1
+		}
+		p.Aggregate, err = csv2htmltable.ParseAggregate(pivotAgg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing pivotagg: %s\n", err)
+			return 1
+		}
+		htable.Pivot = &p
+	}
+	htable.SpanHeaders = spanHeaders
+	htable.Transpose = transpose
+	htable.HeaderIDs = headerIDs
+	htable.Description = description
+	htable.ColumnClasses, err = csv2htmltable.ParseColumnClasses(colClasses)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing colclasses: %s\n", err)
+		return 1
+	}
+	htable.ContentModes, err = csv2htmltable.ParseContentModes(content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing content: %s\n", err)
+		return 1
+	}
+	htable.Links, err = csv2htmltable.ParseLinks(links)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing links: %s\n", err)
+		return 1
+	}
+	htable.Renderers, err = parseRenderers(images, badges, icons)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing renderers: %s\n", err)
+		return 1
+	}
+	if rules != "" {
+		htable.Rules, err = readRules(rules)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading rules: %s\n", err)
+			return 1
+		}
+	}
+	htable.ColorScales, err = csv2htmltable.ParseColorScales(heatmap)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing heatmap: %s\n", err)
+		return 1
+	}
+	cols, err := csv2htmltable.ParseColumns(dataBars)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing databars: %s\n", err)
+		return 1
+	}
+	for _, k := range cols {
+		if htable.DataBars == nil {
+			htable.DataBars = make(map[string]csv2htmltable.DataBar)
+		}
+		htable.DataBars[k] = csv2htmltable.DataBar{}
+	}
+	if sparkRange != "" {
+		sl, err := parseSparkline(sparkRange)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing sparkline: %s\n", err)
+			return 1
+		}
+		if sparkBars {
+			sl.Type = csv2htmltable.SparklineBar
+		}
+		htable.Sparklines = []csv2htmltable.Sparkline{sl}
+	}
+	if chartType != "" {
+		c := csv2htmltable.Chart{Category: chartCategory}
+		c.Type, err = csv2htmltable.ParseChartType(chartType)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing chart: %s\n", err)
+			return 1
+		}
+		c.Values, err = csv2htmltable.ParseColumns(chartValues)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing chartvalues: %s\n", err)
+			return 1
+		}
+		htable.Chart = &c
+	}
+	if theme != "" {
+		htable.Theme, err = csv2htmltable.ParseTheme(theme)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing theme: %s\n", err)
+			return 1
+		}
+		htable.IncludeCSS = includeCSS
+	}
+	htable.LinkRel = linkRel
+	htable.LinkTarget = linkTarget
+	htable.MergeColumns, err = csv2htmltable.ParseColumns(merge)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing merge: %s\n", err)
+		return 1
+	}
+	htable.Sortable = sortable
+	htable.Filterable = filterable
+	htable.ColumnFilters = columnFilters
+	htable.ScriptNonce = nonce
+	htable.StyleNonce = nonce
 	r := csv.NewReader(in)
+	if lint {
+		htable.CSV, err = r.ReadAll()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading CSV: %s\n", err)
+			return 1
+		}
+		return printFindings(out, htable.Lint())
+	}
+	if pageSize > 0 {
+		err = htable.WritePagesFrom(r, pageDir, pageSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing HTML table pages: %s\n", err)
+			printExprContext(err)
+			return 1
+		}
+		return 0
+	}
 	htable.CSV, err = r.ReadAll()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading CSV: %s\n", err)
 		return 1
 	}
+	if chartSVG {
+		err = htable.WriteChart(out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing chart: %s\n", err)
+			printExprContext(err)
+			return 1
+		}
+		return 0
+	}
 	err = htable.Write(out)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing HTML table: %s\n", err)
+		printExprContext(err)
 		return 1
 	}
 	return 0
 }
+
+// printFindings writes the lint findings, one per line, and returns the exit
+// code: 1 if any of the findings are errors, otherwise 0.
+func printFindings(w io.Writer, fs []csv2htmltable.Finding) int {
+	code := 0
+	for _, f := range fs {
+		fmt.Fprintln(w, f)
+		if f.Severity == csv2htmltable.SeverityError {
+			code = 1
+		}
+	}
+	return code
+}
+
+// printExprContext shows where the error is in the filter expression, if
+// the error is an expression error.
+func printExprContext(err error) {
+	if e, ok := err.(*csv2htmltable.ExprError); ok {
+		fmt.Fprintln(os.Stderr, e.Context())
+	}
+}
+
+// parseRenderers returns the Renderers for the -images, -badges, and -icons
+// flags.
+func parseRenderers(images, badges, icons string) (map[string]csv2htmltable.Renderer, error) {
+	renderers := make(map[string]csv2htmltable.Renderer)
+	cols, err := csv2htmltable.ParseColumns(images)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range cols {
+		var img csv2htmltable.Image
+		if i := strings.Index(k, "="); i >= 0 {
+			k, img.Alt = strings.TrimSpace(k[:i]), strings.TrimSpace(k[i+1:])
+		}
+		renderers[k] = img
+	}
+	cols, err = csv2htmltable.ParseColumns(badges)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range cols {
+		renderers[k] = csv2htmltable.Badge{Prefix: "badge-"}
+	}
+	cols, err = csv2htmltable.ParseColumns(icons)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range cols {
+		renderers[k] = csv2htmltable.BoolIcon{}
+	}
+	return renderers, nil
+}
+
+// readRules reads the conditional formatting rules in the named file.
+func readRules(name string) ([]csv2htmltable.Rule, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return csv2htmltable.ReadRules(f)
+}
+
+// parseSparkline parses the -sparkline flag: [header=]from:to.
+func parseSparkline(s string) (csv2htmltable.Sparkline, error) {
+	var sl csv2htmltable.Sparkline
+	if i := strings.Index(s, "="); i >= 0 {
+		sl.Header, s = strings.TrimSpace(s[:i]), s[i+1:]
+	}
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return sl, fmt.Errorf("invalid range %q: expected from:to", s)
+	}
+	sl.From, sl.To = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+	return sl, nil
+}
@@ -0,0 +1,167 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var errColumnSpan = errors.New("column definitions span more columns than the table has")
+
+// ColumnDef defines a col element of the table's colgroup, which lets
+// stylesheets target columns without nth-child selectors.
+type ColumnDef struct {
+	Span  int    // the number of columns the col spans; 0 is the same as 1
+	Class string // the col's class
+	Width string // the columns' CSS width, e.g. "10em"
+	Style string // additional CSS declarations
+}
+
+// ColumnClasses are the classes that are derived from each column and added
+// to its col element.
+type ColumnClasses int
+
+// The derived column classes; they can be combined, e.g.
+// ColTypeClass|ColNameClass.
+const (
+	ColTypeClass ColumnClasses = 1 << iota // "col-" followed by the column's type, e.g. col-numeric
+	ColNameClass                           // "col-" followed by the header, e.g. col-unit-price
+)
+
+// ParseColumnClasses parses a comma separated list of derived column
+// classes: "type", "name", or both.
+func ParseColumnClasses(s string) (ColumnClasses, error) {
+	kinds, err := ParseColumns(s)
+	if err != nil {
+		return 0, err
+	}
+	var c ColumnClasses
+	for _, k := range kinds {
+		switch strings.ToLower(k) {
+		case "type":
+			c |= ColTypeClass
+		case "name":
+			c |= ColNameClass
+		default:
+			return 0, fmt.Errorf("unknown column class %q: expected type or name", k)
+		}
+	}
+	return c, nil
+}
+
+// colGroup computes the attributes of the colgroup's col elements.  If
+// ColumnClasses is set, there's a col for each column, with the classes of
+// the ColumnDef that spans it, if there is one, followed by its derived
+// classes; otherwise there's a col for each ColumnDef.
+func (h *HTMLTable) colGroup() error {
+	h.cols = h.cols[:0]
+	var n int
+	for _, d := range h.ColumnDefs {
+		n += d.span()
+	}
+	if n > h.Cols {
+		return fmt.Errorf("%s: %d > %d", errColumnSpan, n, h.Cols)
+	}
+	if h.ColumnClasses == 0 {
+		for _, d := range h.ColumnDefs {
+			h.cols = append(h.cols, d.attrs(d.span(), "").HTMLAttr())
+		}
+		return nil
+	}
+	defs := make([]ColumnDef, 0, h.Cols)
+	for _, d := range h.ColumnDefs {
+		for i := 0; i < d.span(); i++ {
+			defs = append(defs, d)
+		}
+	}
+	for j := 0; j < h.Cols; j++ {
+		var d ColumnDef
+		if j < len(defs) {
+			d = defs[j]
+		}
+		h.cols = append(h.cols, d.attrs(1, h.derivedClasses(j)).HTMLAttr())
+	}
+	return nil
+}
+
+// colGroupAttrs returns the attributes of each col element.
+func (h *HTMLTable) colGroupAttrs() []template.HTMLAttr {
+	return h.cols
+}
+
+func (d ColumnDef) span() int {
+	if d.Span < 1 {
+		return 1
+	}
+	return d.Span
+}
+
+// attrs returns the col element's attributes; the derived classes follow the
+// ColumnDef's class.
+func (d ColumnDef) attrs(span int, derived string) attrs {
+	var a attrs
+	if span > 1 {
+		a = append(a, attr{"span", strconv.Itoa(span)})
+	}
+	class := strings.TrimSpace(d.Class + " " + derived)
+	if class != "" {
+		a = append(a, attr{"class", class})
+	}
+	var style []string
+	if d.Width != "" {
+		style = append(style, "width: "+d.Width)
+	}
+	if d.Style != "" {
+		style = append(style, d.Style)
+	}
+	if len(style) > 0 {
+		a = append(a, attr{"style", strings.Join(style, "; ")})
+	}
+	return a
+}
+
+// derivedClasses returns the j'th column's derived classes.
+func (h *HTMLTable) derivedClasses(j int) string {
+	var classes []string
+	if h.ColumnClasses&ColTypeClass != 0 {
+		classes = append(classes, "col-"+h.ColumnType(j).String())
+	}
+	if h.ColumnClasses&ColNameClass != 0 {
+		name := strconv.Itoa(j)
+		if len(h.HeaderRows) > 0 && j < len(h.HeaderRows[0]) {
+			if s := classSlug(h.HeaderRows[0][j]); s != "" {
+				name = s
+			}
+		}
+		classes = append(classes, "col-"+name)
+	}
+	return strings.Join(classes, " ")
+}
+
+// classSlug returns s as a class name: lowercase, with each run of
+// characters that aren't letters or digits replaced by a "-".
+func classSlug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// IsColumnSpanErr returns whether or not the error was a result of column
+// definitions that span more columns than the table has.
+func IsColumnSpanErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errColumnSpan.Error())
+}
@@ -0,0 +1,103 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestColGroup(t *testing.T) {
+	tests := []struct {
+		ColumnDefs    []ColumnDef
+		ColumnClasses ColumnClasses
+		Expected      string
+		ExpectedErr   string
+	}{
+		{ // 0
+			Expected: "</caption>\n    <thead>",
+		},
+		{ // 1
+			ColumnDefs: []ColumnDef{
+				{Class: "name", Width: "10em"},
+				{Span: 2, Class: "money", Style: "text-align: right"},
+			},
+			Expected: `
+    <colgroup>
+        <col class="name" style="width: 10em">
+        <col span="2" class="money" style="text-align: right">
+    </colgroup>`,
+		},
+		{ // 2
+			ColumnDefs:    []ColumnDef{{Class: "name"}},
+			ColumnClasses: ColTypeClass | ColNameClass,
+			Expected: `
+    <colgroup>
+        <col class="name col-text col-product-name">
+        <col class="col-numeric col-unit-price">
+        <col class="col-date col-sold">
+    </colgroup>`,
+		},
+		{ // 3
+			ColumnDefs:  []ColumnDef{{Span: 2}, {Span: 2}},
+			ExpectedErr: "column definitions span more columns than the table has: 4 > 3",
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.Caption = "Sales"
+		h.ColumnDefs = test.ColumnDefs
+		h.ColumnClasses = test.ColumnClasses
+		h.CSV = [][]string{
+			[]string{"Product Name", "Unit Price ($)", "Sold"},
+			[]string{"Widget", "1.50", "2016-01-02"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil: want %q", i, test.ExpectedErr)
+			continue
+		}
+		if !strings.Contains(buf.String(), test.Expected) {
+			t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), test.Expected)
+		}
+	}
+}
+
+func TestParseColumnClasses(t *testing.T) {
+	tests := []struct {
+		Value       string
+		Expected    ColumnClasses
+		ExpectedErr string
+	}{
+		{"", 0, ""},
+		{"type", ColTypeClass, ""},
+		{"Name, type", ColTypeClass | ColNameClass, ""},
+		{"width", 0, `unknown column class "width": expected type or name`},
+	}
+	for i, test := range tests {
+		c, err := ParseColumnClasses(test.Value)
+		if err != nil {
+			if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		if c != test.Expected {
+			t.Errorf("%d: got %d; want %d", i, c, test.Expected)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package csv2htmltable
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errUnknownColumn = errors.New("unknown column")
+
+// columnIndex returns the index of the column identified by k, which is
+// either a value in the first header row, the original header of a column
+// that has been renamed, or a 0-based column index.  If k doesn't identify a
+// column, -1 is returned.
+func (h *HTMLTable) columnIndex(k string) int {
+	if len(h.HeaderRows) > 0 {
+		for i, v := range h.HeaderRows[0] {
+			if v == k {
+				return i
+			}
+		}
+	}
+	for i, v := range h.names {
+		if v == k {
+			return i
+		}
+	}
+	i, err := strconv.Atoi(k)
+	if err != nil || i < 0 || i >= h.Cols {
+		return -1
+	}
+	return i
+}
+
+// project applies Columns, and then Rename, to the header rows and records.
+// Columns are resolved against the columns as they are in the CSV; because
+// Rename is applied afterwards, a 0-based index in Rename refers to the
+// column's position in the output.
+func (h *HTMLTable) project() error {
+	h.srcIdx = make([]int, h.Cols)
+	for i := range h.srcIdx {
+		h.srcIdx[i] = i
+	}
+	if len(h.Columns) > 0 {
+		idx := make([]int, len(h.Columns))
+		for i, k := range h.Columns {
+			idx[i] = h.columnIndex(k)
+			if idx[i] < 0 {
+				return unknownColumnErr(k)
+			}
+		}
+		h.HeaderRows = projectRows(h.HeaderRows, idx)
+		h.CSV = projectRows(h.CSV, idx)
+		if h.pivotTotal != nil {
+			h.pivotTotal = projectRows([][]string{h.pivotTotal}, idx)[0]
+		}
+		if len(h.HeaderRows) > 0 {
+			h.names = h.HeaderRows[0]
+		}
+		h.Cols = len(idx)
+		h.srcIdx = idx
+	}
+	if len(h.Rename) == 0 || len(h.HeaderRows) == 0 {
+		return nil
+	}
+	row := make([]string, len(h.HeaderRows[0]))
+	copy(row, h.HeaderRows[0])
+	for k, v := range h.Rename {
+		i := h.columnIndex(k)
+		if i < 0 || i >= len(row) {
+			return unknownColumnErr(k)
+		}
+		row[i] = v
+	}
+	h.HeaderRows = append([][]string{row}, h.HeaderRows[1:]...)
+	return nil
+}
+
+// outputIndex returns the index in the output of the i'th column in the CSV.
+// If the column isn't output, -1 is returned.
+func (h *HTMLTable) outputIndex(i int) int {
+	for j, v := range h.srcIdx {
+		if v == i {
+			return j
+		}
+	}
+	return -1
+}
+
+// projectRows returns a copy of rows that only has the fields at the received
+// indexes, in that order.  Fields that a row doesn't have are empty.
+func projectRows(rows [][]string, idx []int) [][]string {
+	out := make([][]string, len(rows))
+	for i, row := range rows {
+		out[i] = make([]string, len(idx))
+		for j, k := range idx {
+			if k < len(row) {
+				out[i][j] = row[k]
+			}
+		}
+	}
+	return out
+}
+
+// ParseColumns parses a comma separated list of column keys; keys containing
+// commas can be quoted as in CSV.
+func ParseColumns(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	r := csv.NewReader(strings.NewReader(s))
+	r.TrimLeadingSpace = true
+	cols, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i := range cols {
+		cols[i] = strings.TrimSpace(cols[i])
+	}
+	return cols, nil
+}
+
+// ParseRename parses a comma separated list of old=new column renames, e.g.
+// "amt=Amount,qty=Quantity".  Pairs containing commas can be quoted as in
+// CSV.
+func ParseRename(s string) (map[string]string, error) {
+	pairs, err := ParseColumns(s)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		i := strings.Index(p, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid pair %q: expected key=value", p)
+		}
+		m[strings.TrimSpace(p[:i])] = strings.TrimSpace(p[i+1:])
+	}
+	return m, nil
+}
+
+func unknownColumnErr(k string) error {
+	return fmt.Errorf("%s: %q", errUnknownColumn, k)
+}
+
+// IsUnknownColumnErr returns whether or not the error was a result of a
+// column key that doesn't identify a column.
+func IsUnknownColumnErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errUnknownColumn.Error())
+}
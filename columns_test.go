@@ -0,0 +1,178 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"testing"
+
+	json "github.com/mohae/unsafejson"
+)
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		Columns            []string
+		Rename             map[string]string
+		HeaderRows         [][]string
+		ExpectedHeaderRows [][]string
+		ExpectedCSV        [][]string
+		ExpectedErr        string
+	}{
+		{ // 0
+			ExpectedHeaderRows: [][]string{[]string{"Name", "amt", "Email"}},
+			ExpectedCSV: [][]string{
+				[]string{"Bob", "10", "bob@example.com"},
+				[]string{"Genvieve", "9", "genvieve@example.com"},
+			},
+		},
+		{ // 1
+			Columns:            []string{"Email", "0"},
+			ExpectedHeaderRows: [][]string{[]string{"Email", "Name"}},
+			ExpectedCSV: [][]string{
+				[]string{"bob@example.com", "Bob"},
+				[]string{"genvieve@example.com", "Genvieve"},
+			},
+		},
+		{ // 2
+			Columns:            []string{"Name", "amt"},
+			Rename:             map[string]string{"amt": "Amount"},
+			ExpectedHeaderRows: [][]string{[]string{"Name", "Amount"}},
+			ExpectedCSV: [][]string{
+				[]string{"Bob", "10"},
+				[]string{"Genvieve", "9"},
+			},
+		},
+		{ // 3
+			Rename:             map[string]string{"0": "Who"},
+			ExpectedHeaderRows: [][]string{[]string{"Who", "amt", "Email"}},
+			ExpectedCSV: [][]string{
+				[]string{"Bob", "10", "bob@example.com"},
+				[]string{"Genvieve", "9", "genvieve@example.com"},
+			},
+		},
+		{ // 4
+			Columns:     []string{"Total"},
+			ExpectedErr: `unknown column: "Total"`,
+		},
+		{ // 5
+			Rename:      map[string]string{"Total": "Sum"},
+			ExpectedErr: `unknown column: "Total"`,
+		},
+		{ // 6
+			Columns: []string{"amt", "Name"},
+			HeaderRows: [][]string{
+				[]string{"Name", "amt", "Email"},
+				[]string{"Nom", "Montant", "Courriel"},
+			},
+			ExpectedHeaderRows: [][]string{
+				[]string{"amt", "Name"},
+				[]string{"Montant", "Nom"},
+			},
+			ExpectedCSV: [][]string{
+				[]string{"10", "Bob"},
+				[]string{"9", "Genvieve"},
+			},
+		},
+	}
+	h := New("test")
+	for i, test := range tests {
+		h.Reset()
+		h.Columns = test.Columns
+		h.Rename = test.Rename
+		h.HeaderRows = test.HeaderRows
+		h.CSV = [][]string{
+			[]string{"Name", "amt", "Email"},
+			[]string{"Bob", "10", "bob@example.com"},
+			[]string{"Genvieve", "9", "genvieve@example.com"},
+		}
+		err := h.process()
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if err.Error() != test.ExpectedErr || !IsUnknownColumnErr(err) {
+				t.Errorf("%d: got %q want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil: want %q", i, test.ExpectedErr)
+			continue
+		}
+		if json.MarshalToString(h.HeaderRows) != json.MarshalToString(test.ExpectedHeaderRows) {
+			t.Errorf("%d: got %v; want %v", i, h.HeaderRows, test.ExpectedHeaderRows)
+		}
+		if json.MarshalToString(h.CSV) != json.MarshalToString(test.ExpectedCSV) {
+			t.Errorf("%d: got %v; want %v", i, h.CSV, test.ExpectedCSV)
+		}
+		if h.Cols != len(test.ExpectedHeaderRows[0]) {
+			t.Errorf("%d: got %d cols; want %d", i, h.Cols, len(test.ExpectedHeaderRows[0]))
+		}
+	}
+}
+
+func TestProjectRowHeaderAndFooter(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.HasRowHeader = true
+	h.Footer = "footer"
+	h.Columns = []string{"Email", "Name"}
+	h.CSV = [][]string{
+		[]string{"Name", "amt", "Email"},
+		[]string{"Bob", "10", "bob@example.com"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	expected := `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th scope="col">Email</th>
+            <th scope="col">Name</th>
+        </tr>
+    </thead>
+    <tfoot>
+        <tr>
+            <td colspan="2">footer</td>
+        </tr>
+    </tfoot>
+    <tbody>
+        <tr>
+            <th scope="row">bob@example.com</th>
+            <td>Bob</td>
+        </tr>
+    </tbody>
+</table>
+`
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+}
+
+func TestParseRename(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected map[string]string
+		err      bool
+	}{
+		{s: "", expected: map[string]string{}},
+		{s: "amt=Amount", expected: map[string]string{"amt": "Amount"}},
+		{s: `amt=Amount, "qty=Quantity, Total"`, expected: map[string]string{"amt": "Amount", "qty": "Quantity, Total"}},
+		{s: "amt", err: true},
+	}
+	for i, test := range tests {
+		m, err := ParseRename(test.s)
+		if err != nil {
+			if !test.err {
+				t.Errorf("%d: got %q: want nil", i, err)
+			}
+			continue
+		}
+		if test.err {
+			t.Errorf("%d: got nil: want an error", i)
+			continue
+		}
+		if json.MarshalToString(m) != json.MarshalToString(test.expected) {
+			t.Errorf("%d: got %v; want %v", i, m, test.expected)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var errUnknownContentMode = errors.New("unknown content mode")
+
+// ContentMode is how a column's values are rendered in the table's body.
+type ContentMode int
+
+// Supported content modes.
+const (
+	ContentText     ContentMode = iota // the value is text, which is escaped
+	ContentMarkdown                    // the value is inline Markdown
+	ContentHTML                        // the value is HTML, which is sanitized
+	ContentAutoLink                    // the value is text whose URLs and email addresses are links
+)
+
+var contentModeNames = [...]string{"text", "markdown", "html", "autolink"}
+
+func (m ContentMode) String() string {
+	if m < 0 || int(m) >= len(contentModeNames) {
+		return ""
+	}
+	return contentModeNames[m]
+}
+
+// ParseContentMode returns the ContentMode with the received name: "text",
+// "markdown", "html", or "autolink".  "md" is accepted as an alias for
+// markdown and "link" for autolink.
+func ParseContentMode(s string) (ContentMode, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "md":
+		return ContentMarkdown, nil
+	case "link":
+		return ContentAutoLink, nil
+	}
+	for i, name := range contentModeNames {
+		if name == s {
+			return ContentMode(i), nil
+		}
+	}
+	return ContentText, fmt.Errorf("%s: %q", errUnknownContentMode, s)
+}
+
+// ParseContentModes parses a comma separated list of column=mode pairs, e.g.
+// "Notes=markdown,Bio=html".
+func ParseContentModes(s string) (map[string]ContentMode, error) {
+	pairs, err := ParseRename(s)
+	if err != nil {
+		return nil, err
+	}
+	modes := make(map[string]ContentMode, len(pairs))
+	for k, v := range pairs {
+		modes[k], err = ParseContentMode(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return modes, nil
+}
+
+// cellContent returns the content of the j'th cell of the received row.  If
+// the column has a Renderer, it renders the cell's value; otherwise the value
+// is formatted using the column's Formatter and then rendered according to
+// the column's ContentMode.  If the column has a Link, the content is wrapped
+// in it.
+func (h *HTMLTable) cellContent(rec []string, j int) template.HTML {
+	v := field(rec, j)
+	var href string
+	var linked bool
+	if l := h.links[j]; l != nil && v != "" {
+		href, linked = l.href(rec, j, h.sanitizer().URLSchemes)
+	}
+	var content template.HTML
+	if r := h.renderers[j]; r != nil {
+		content = r.Render(v, h.rowField(rec))
+	} else {
+		content = h.renderContent(j, h.formatField(j, v), !linked)
+	}
+	content = h.barSVG(rec, j) + content
+	if linked {
+		l := h.links[j]
+		return template.HTML(h.anchor(href, l.Rel, l.Target, string(content)))
+	}
+	return content
+}
+
+// renderContent renders a value according to the j'th column's ContentMode.
+// Values are only auto-linked if links is true, as links can't be nested.
+func (h *HTMLTable) renderContent(j int, v string, links bool) template.HTML {
+	switch h.contentMode(j) {
+	case ContentMarkdown:
+		return Markdown(v, h.sanitizer().URLSchemes)
+	case ContentHTML:
+		return h.sanitizer().Sanitize(v)
+	case ContentAutoLink:
+		if links {
+			return h.autoLink(v)
+		}
+	}
+	return template.HTML(template.HTMLEscapeString(v))
+}
+
+// setContentModes resolves the ContentModes against the output columns.
+func (h *HTMLTable) setContentModes() error {
+	h.modes = nil
+	if len(h.ContentModes) == 0 {
+		return nil
+	}
+	h.modes = make(map[int]ContentMode, len(h.ContentModes))
+	for k, m := range h.ContentModes {
+		i := h.columnIndex(k)
+		if i < 0 {
+			return unknownColumnErr(k)
+		}
+		h.modes[i] = m
+	}
+	return nil
+}
+
+// contentMode returns the ContentMode of the j'th column.
+func (h *HTMLTable) contentMode(j int) ContentMode {
+	return h.modes[j]
+}
+
+// sanitizer returns the table's Sanitizer, or, if it doesn't have one, the
+// DefaultSanitizer.
+func (h *HTMLTable) sanitizer() *Sanitizer {
+	if h.Sanitizer != nil {
+		return h.Sanitizer
+	}
+	return DefaultSanitizer
+}
+
+// The inline Markdown syntax, which is matched against escaped text.
+var (
+	mdCode   = regexp.MustCompile("`([^`]+)`")
+	mdLink   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdStrong = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdEm     = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	// The placeholder of a rendered link; see markdownSpan.
+	mdPlaceholder = regexp.MustCompile("\x00[0-9]+\x00")
+)
+
+// Markdown renders inline Markdown as HTML: **strong**, *emphasis*,
+// `code`, and [links](url).  The text is escaped before it's rendered, so the
+// result only contains the elements that the Markdown produces.  Links whose
+// URL has a scheme that isn't in schemes are rendered as their text.
+func Markdown(s string, schemes []string) template.HTML {
+	// Code spans are rendered as is, so the text is split on them and only
+	// the text between them is rendered.
+	var b strings.Builder
+	esc := template.HTMLEscapeString(s)
+	last := 0
+	for _, m := range mdCode.FindAllStringSubmatchIndex(esc, -1) {
+		b.WriteString(markdownSpan(esc[last:m[0]], schemes))
+		b.WriteString("<code>" + esc[m[2]:m[3]] + "</code>")
+		last = m[1]
+	}
+	b.WriteString(markdownSpan(esc[last:], schemes))
+	return template.HTML(b.String())
+}
+
+// markdownSpan renders the links, strong, and emphasis of escaped text.  The
+// links are replaced by placeholders while the strong and emphasis are
+// rendered so that they aren't rendered inside of the links' URLs; escaped
+// text can't contain the NUL bytes that delimit the placeholders.
+func markdownSpan(s string, schemes []string) string {
+	var links []string
+	s = mdLink.ReplaceAllStringFunc(s, func(m string) string {
+		sub := mdLink.FindStringSubmatch(m)
+		link := markdownEmphasis(sub[1])
+		if allowedURL(html.UnescapeString(sub[2]), schemes) {
+			link = `<a href="` + sub[2] + `">` + link + "</a>"
+		}
+		links = append(links, link)
+		return "\x00" + strconv.Itoa(len(links)-1) + "\x00"
+	})
+	s = markdownEmphasis(s)
+	return mdPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		i, _ := strconv.Atoi(m[1 : len(m)-1])
+		return links[i]
+	})
+}
+
+// markdownEmphasis renders the strong and emphasis of escaped text.
+func markdownEmphasis(s string) string {
+	s = mdStrong.ReplaceAllString(s, "<strong>$1$2</strong>")
+	return mdEm.ReplaceAllString(s, "<em>$1$2</em>")
+}
+
+// DefaultURLSchemes are the URL schemes that links and images may use, by
+// default.  URLs without a scheme, i.e. relative URLs, are always allowed.
+var DefaultURLSchemes = []string{"http", "https", "mailto"}
+
+// allowedURL returns whether the URL is relative or has one of the schemes.
+// If schemes is nil, the DefaultURLSchemes are used.
+func allowedURL(u string, schemes []string) bool {
+	if schemes == nil {
+		schemes = DefaultURLSchemes
+	}
+	p, err := url.Parse(strings.TrimSpace(u))
+	if err != nil {
+		return false
+	}
+	if p.Scheme == "" {
+		// A relative URL can't have a colon before its first slash, or it would
+		// be read as a scheme.
+		return !strings.Contains(strings.SplitN(p.Path, "/", 2)[0], ":")
+	}
+	for _, s := range schemes {
+		if strings.EqualFold(p.Scheme, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnknownContentModeErr returns whether or not the error was a result of
+// an unknown content mode name.
+func IsUnknownContentModeErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errUnknownContentMode.Error())
+}
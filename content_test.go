@@ -0,0 +1,136 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		Value    string
+		Expected template.HTML
+	}{
+		{"plain <text>", "plain &lt;text&gt;"},                                                            // 0
+		{"**bold** and __bold__", "<strong>bold</strong> and <strong>bold</strong>"},                      // 1
+		{"*em* and _em_", "<em>em</em> and <em>em</em>"},                                                  // 2
+		{"snake_case_name", "snake_case_name"},                                                            // 3
+		{"`a **b** <c>`", "<code>a **b** &lt;c&gt;</code>"},                                               // 4
+		{"[docs](https://example.com/a?b=1&c=2)", `<a href="https://example.com/a?b=1&amp;c=2">docs</a>`}, // 5
+		{"[x](javascript:alert(1))", "x)"},                                                                // 6
+		{"[**x**](/rel)", `<a href="/rel"><strong>x</strong></a>`},                                        // 7
+		{`[x](https://e.com/"onmouseover="y)`, `<a href="https://e.com/&#34;onmouseover=&#34;y">x</a>`},   // 8
+		{"[a](http://x/*y*z) *b*", `<a href="http://x/*y*z">a</a> <em>b</em>`},                            // 9
+		{"**[a](/__init__)**", `<strong><a href="/__init__">a</a></strong>`},                              // 10
+	}
+	for i, test := range tests {
+		got := Markdown(test.Value, nil)
+		if got != test.Expected {
+			t.Errorf("%d: got %q; want %q", i, got, test.Expected)
+		}
+	}
+}
+
+func TestContentModes(t *testing.T) {
+	tests := []struct {
+		Modes       map[string]ContentMode
+		Expected    []string
+		ExpectedErr string
+	}{
+		{ // 0
+			Expected: []string{
+				"<td>**Widget**</td>",
+				"<td>&lt;b&gt;new&lt;/b&gt;&lt;script&gt;x()&lt;/script&gt;</td>",
+			},
+		},
+		{ // 1
+			Modes: map[string]ContentMode{"Name": ContentMarkdown, "Notes": ContentHTML},
+			Expected: []string{
+				"<td><strong>Widget</strong></td>",
+				"<td><b>new</b></td>",
+			},
+		},
+		{ // 2
+			Modes: map[string]ContentMode{"1": ContentMarkdown},
+			Expected: []string{
+				"<td>**Widget**</td>",
+				"<td>&lt;b&gt;new&lt;/b&gt;&lt;script&gt;x()&lt;/script&gt;</td>",
+			},
+		},
+		{ // 3
+			Modes:       map[string]ContentMode{"Nope": ContentMarkdown},
+			ExpectedErr: `unknown column: "Nope"`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.ContentModes = test.Modes
+		h.CSV = [][]string{
+			[]string{"Name", "Notes"},
+			[]string{"**Widget**", "<b>new</b><script>x()</script>"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q; want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		for _, v := range test.Expected {
+			if !strings.Contains(buf.String(), v) {
+				t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), v)
+			}
+		}
+	}
+}
+
+func TestParseContentModes(t *testing.T) {
+	tests := []struct {
+		Value       string
+		Expected    map[string]ContentMode
+		ExpectedErr string
+	}{
+		{"", map[string]ContentMode{}, ""}, // 0
+		{"Notes=markdown, Bio=HTML,x=md,y=text", map[string]ContentMode{"Notes": ContentMarkdown, "Bio": ContentHTML, "x": ContentMarkdown, "y": ContentText}, ""}, // 1
+		{"Notes=rich", nil, `unknown content mode: "rich"`},        // 2
+		{"Notes", nil, `invalid pair "Notes": expected key=value`}, // 3
+	}
+	for i, test := range tests {
+		modes, err := ParseContentModes(test.Value)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q; want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		if len(modes) != len(test.Expected) {
+			t.Errorf("%d: got %v; want %v", i, modes, test.Expected)
+			continue
+		}
+		for k, m := range test.Expected {
+			if modes[k] != m {
+				t.Errorf("%d: %s: got %s; want %s", i, k, modes[k], m)
+			}
+		}
+	}
+	if !IsUnknownContentModeErr(fmt.Errorf("%s: %q", errUnknownContentMode, "x")) {
+		t.Error("IsUnknownContentModeErr: got false; want true")
+	}
+}
@@ -3,8 +3,10 @@ package csv2htmltable
 import (
 	"errors"
 	"fmt"
+	"html"
 	"html/template"
 	"io"
+	"strings"
 )
 
 // DefaultHTag is the default value for the Heading Element.
@@ -12,6 +14,7 @@ const DefaultHTag = "h4"
 
 var errTableHeader = errors.New("no table header information found")
 var errNoData = errors.New("no table data found")
+var errNoTableID = errors.New("table ID required for filtering")
 
 var tableTpl = `
 {{- if .Section.Include}}
@@ -25,53 +28,114 @@ var tableTpl = `
 <section>
     {{- end}}
 {{- end}}
-{{- if .HeadingText}}
-{{ htag .HeadingTag .HeadingText}}
+{{- with css $}}
+<style{{if $.StyleNonce}} nonce="{{$.StyleNonce}}"{{end}}>
+{{.}}</style>
 {{- end}}
-<table{{if .Class}} class="{{.Class}}"{{end}}{{if .ID}} id="{{.ID}}"{{end}} border="{{.Border}}">
-{{- if .Caption}}
-    <caption>{{.Caption}}</caption>
+{{- if or .HeadingText .HeadingHTML}}
+{{ heading $}}
 {{- end}}
-{{- if $.HasHeader }}
-    <thead>
-    {{- range $i, $row := .HeaderRows}}
-        {{- range $fld := $row}}
-            {{- if eq $i 0}}
-        <th>{{$fld}}</th>
+{{- if .Description}}
+<p id="{{descid $}}">{{.Description}}</p>
+{{- end}}
+{{- with chart $}}
+{{.}}
+{{- end}}
+{{- if .Filterable}}
+<input type="search" id="{{.ID}}-search" aria-label="Filter table" aria-controls="{{.ID}}">
+{{- end}}
+<table{{with tableclass $}} class="{{.}}"{{end}}{{if .ID}} id="{{.ID}}"{{end}}{{with describedby $}} aria-describedby="{{.}}"{{end}} border="{{.Border}}">
+{{- if or .Caption .CaptionHTML}}
+    <caption{{class $ "caption"}}>{{caption $}}</caption>
+{{- end}}
+{{- with colgroup $}}
+    <colgroup>
+    {{- range .}}
+        <col{{.}}>
+    {{- end}}
+    </colgroup>
+{{- end}}
+{{- if and $.HasHeader .HeaderRows}}
+    <thead{{class $ "thead"}}>
+    {{- range headrows $}}
+        <tr{{class $ "tr"}}>
+        {{- range .}}
+            {{- if .Header}}
+            <th{{.Attrs}}>{{.Text}}</th>
             {{- else}}
-        <td>{{$fld}}</td>
+            <td{{class $ "td"}}>{{.Text}}</td>
             {{- end}}
         {{- end}}
+        </tr>
+    {{- end}}
+    {{- if .ColumnFilters}}
+        <tr{{class $ "tr"}}>
+        {{- range $j, $fld := index .HeaderRows 0}}
+            <td{{class $ "td"}}><input type="search" aria-label="Filter {{$fld}}" aria-controls="{{$.ID}}" data-col="{{$j}}"></td>
+        {{- end}}
+        </tr>
     {{- end}}
     </thead>
 {{- end}}
-{{- if $.Footer}}
-    <tfoot>
-        <tr>
-            <td colspan="{{$.Cols}}">{{$.Footer}}</td>
+{{- if or $.Footer (footrows $)}}
+    <tfoot{{class $ "tfoot"}}>
+    {{- range footrows $}}
+        <tr{{class $ "tr" .Class}}>
+        {{- range $ndx, $field := .Cells}}
+            {{- if rowhead $ $ndx}}
+            <th scope="row"{{class $ "th"}}>{{$field}}</th>
+            {{- else}}
+            <td{{class $ "td"}}>{{$field}}</td>
+            {{- end}}
+        {{- end}}
         </tr>
+    {{- end}}
+    {{- if $.Footer}}
+        <tr{{class $ "tr"}}>
+            <td colspan="{{$.Cols}}"{{class $ "td"}}>{{$.Footer}}</td>
+        </tr>
+    {{- end}}
     </tfoot>
 {{- end}}
-{{- range $index, $record := .CSV -}}
-    {{- if eq $index 0}}
-    <tbody>
+{{- range $g := groups $}}
+    <tbody{{class $ "tbody"}}>
+    {{- if $g.Label}}
+        <tr{{class $ "tr" "group"}}>
+            <th colspan="{{$.Cols}}" scope="rowgroup"{{class $ "th"}}>{{$g.Label}}</th>
+        </tr>
     {{- end}}
-        <tr>
-    {{- range $ndx, $field := $record}}
-        {{- if eq $ndx 0}}
-            {{- if $.HasRowHeader}}
-            <th>{{$field}}</th>
-            {{- else}}
-            <td>{{$field}}</td>
-            {{- end}}
+{{- range bodyrows $ $g}}
+        <tr{{.Attrs}}>
+    {{- range .Cells}}
+        {{- if .Header}}
+            <th{{.Attrs}}>{{.Content}}</th>
         {{- else}}
-            <td>{{$field}}</td>
+            <td{{.Attrs}}>{{.Content}}</td>
         {{- end}}
     {{- end}}
         </tr>
 {{- end}}
+    {{- with $g.Subtotal}}
+        <tr{{class $ "tr" "subtotal"}}>
+        {{- range $ndx, $field := .}}
+            {{- if rowhead $ $ndx}}
+            <th scope="row"{{class $ "th"}}>{{$field}}</th>
+            {{- else}}
+            <td{{class $ "td"}}>{{$field}}</td>
+            {{- end}}
+        {{- end}}
+        </tr>
+    {{- end}}
     </tbody>
+{{- end}}
 </table>
+{{- if .Sortable}}
+{{template "sort" .}}
+{{- end}}
+{{- if or .Filterable .ColumnFilters}}
+<p id="{{.ID}}-status" role="status" aria-live="polite">{{len .CSV}} of {{len .CSV}} rows</p>
+{{template "filter" .}}
+{{- end}}
 {{- if .Section.Include}}
 </section>
 {{- end}}
@@ -100,9 +164,81 @@ type Section struct {
 // 1 and 6, inclusive.  If the value is invalid, it will be set to 4, the
 // default, which corresponds with <h4>.
 //
+// All of the table's text, including the heading and caption, is escaped.
+// Trusted HTML can be used for the heading and caption by setting HeadingHTML
+// and CaptionHTML instead.
+//
 // Optionally, the table can wrapped in a section by setting the
 // Section.Include field to true.
 //
+// If Sortable is true, a small inline script is included after the table
+// that lets the user sort the table's body rows by clicking on a column's
+// header.  Columns are sorted according to their type.  If the page is served
+// with a Content-Security-Policy, ScriptNonce should be set to the policy's
+// nonce.
+//
+// If Filterable is true, a search input is written before the table, and if
+// ColumnFilters is true, a row of filter inputs is added to the table
+// header, one for each column.  Rows that don't contain the search text, or
+// whose fields don't contain their column's filter text, are hidden; the
+// matching is case-insensitive.  The number of rows shown is announced to
+// screen readers in a live status region that follows the table.  The inputs
+// are bound to the table by its ID, so the ID must be set.
+//
+// The body rows can be grouped by the values of one or more columns by
+// setting GroupBy.  Each group is rendered as its own tbody, which starts
+// with a header row for the group.  If Subtotals is set, each group ends with
+// a subtotal row and a grand total row is added to the table's footer.
+//
+// FooterRows adds computed rows to the table's footer, with each column's
+// cell holding an aggregate, e.g. the sum, of that column's values.  Formats
+// sets the Formatter of a column, which is used for both its body values and
+// its aggregates.
+//
+// If Pivot is set, the records are turned into a crosstab before they are
+// sorted and output; multi-level column keys result in spanned, hierarchical,
+// header rows.  SpanHeaders spans the header rows of other tables.
+//
+// MergeColumns merges the consecutive identical values of columns, e.g. the
+// leading columns of a sorted report, into row header cells with a rowspan.
+// Because the merged cells aren't in every row, each body cell lists its
+// column's header and its row's merged cells in its headers attribute; the
+// IDs are prefixed with the table's ID, or, if it doesn't have one, its
+// template's name.  Client-side sorting doesn't keep merged cells intact.
+//
+// For control over individual cells, e.g. their spans, classes, or data
+// attributes, the body can be set as Rows of Cells instead of CSV records;
+// RowsFromRecords converts records to Rows.  The Rows' spans are validated
+// and the transformations of the records, e.g. Where, SortBy, GroupBy, and
+// MergeColumns, don't apply to them.
+//
+// ColumnDefs adds a colgroup with a col element for each definition, with its
+// span, class, and width.  ColumnClasses adds classes derived from each
+// column's type or header, e.g. col-numeric or col-unit-price, so that
+// stylesheets can target columns.
+//
+// Header cells have a scope: col or colgroup in the header rows and row in
+// the body.  Complex tables, e.g. ones with multiple header rows and a row
+// header, can also associate each body cell with its header cells by ID by
+// setting HeaderIDs.  Description adds a description of the table that is
+// referenced by its aria-describedby attribute, as is DescribedBy.
+//
+// If Transpose is true, the table's rows and columns are flipped after all of
+// the other transformations have been applied: each header row becomes a row
+// header column and each record becomes a column.  The Formats are applied
+// before the table is flipped; the options that are keyed by column and
+// applied when the table is rendered, e.g. GroupBy, FooterRows, Links,
+// Rules, and ColumnTypes, can't be used with Transpose, nor can Rows.
+//
+// Theme adds the classes of a stylesheet, e.g. a CSS framework's, to the
+// table's parts, and IncludeCSS inlines its stylesheet.  The built-in Themes
+// are bare, striped, bootstrap, bulma, and tailwind.
+//
+// If Chart is set, an SVG bar, line, or stacked bar chart of the table's
+// data is written before the table, followed by a link to the table, which
+// is the chart's accessible alternative.  WriteChart writes the chart as a
+// standalone SVG document.
+//
 // The table's header rows output is controlled by the HasHeader field.
 // When false, no table headers will be generated.  If the CSV data has
 // record header rows, the HeaderRowNum should be set to the number of
@@ -132,6 +268,138 @@ type HTMLTable struct {
 	// the CSV header records will be ignored.
 	HeaderRows [][]string
 	CSV        [][]string
+	// The body rows, as Cells with their own attributes and spans.  If set,
+	// they are the table's body instead of the CSV's records; see Row.
+	Rows []Row
+	// The type of a column, keyed by either its header or its 0-based index.
+	// The types of columns that aren't in ColumnTypes are inferred.
+	ColumnTypes map[string]ColumnType
+	Sortable    bool // if true, clicking a column's header sorts the table by it
+	// If true, a search input that filters the table's rows precedes the table.
+	Filterable bool
+	// If true, a row of filter inputs, one per column, follows the header rows.
+	ColumnFilters bool
+	ScriptNonce   string // CSP nonce for any inline scripts
+	// The columns to output, in order, identified by either their header or
+	// their 0-based index in the CSV.  If empty, all of the columns are output.
+	Columns []string
+	// Replacement text for the first header row, keyed by either the column's
+	// header or its 0-based index in the output.
+	Rename map[string]string
+	// A filter expression; only the records that match it are output.  See
+	// Expr for the syntax.
+	Where string
+	// The columns to sort the records by, in order of precedence.
+	SortBy []SortKey
+	// Used to compare text when sorting; if nil, text is sorted in natural
+	// order.
+	Collator Collator
+	names    []string // the original headers of the output columns
+	srcIdx   []int    // the index in the CSV of each output column
+	sortCol  int      // the output column that the records are sorted by, or -1
+	sortDesc bool
+	// The columns whose values partition the records into groups; each group
+	// is rendered as its own tbody with a header row.
+	GroupBy []string
+	// The aggregates for each group's subtotal row, and the grand total row,
+	// keyed by column.  If empty, there aren't any subtotal rows.
+	Subtotals map[string]Aggregate
+	// Computed footer rows, e.g. column totals.
+	FooterRows []FooterRow
+	// Formatters for the values of columns, keyed by column.
+	Formats map[string]Formatter
+	// If not nil, the records are turned into a crosstab; see Pivot.
+	Pivot *Pivot
+	// If true, the rows and columns are flipped, e.g. for a single wide record.
+	Transpose bool
+	// The columns whose consecutive identical values are merged into one
+	// cell with a rowspan, in order of hierarchy: each column only merges
+	// within the spans of the columns before it.
+	MergeColumns []string
+	// The col elements of the table's colgroup.
+	ColumnDefs []ColumnDef
+	// The classes derived from each column for its col element; if set,
+	// there's a col for each column.
+	ColumnClasses ColumnClasses
+	// If true, every header cell has an ID and every body cell lists the IDs
+	// of its header cells in its headers attribute.
+	HeaderIDs bool
+	// A description of the table, e.g. how to read it, which is written
+	// before the table and referenced by its aria-describedby attribute.
+	Description string
+	// The IDs of other elements that describe the table, for its
+	// aria-describedby attribute.
+	DescribedBy string
+	// Trusted HTML for the heading's content, e.g. with a link; it is written
+	// as is and takes precedence over HeadingText.  Only set it to HTML from a
+	// trusted source.
+	HeadingHTML template.HTML
+	// Trusted HTML for the caption's content, e.g. with an abbr element; it
+	// is written as is and takes precedence over Caption.  Only set it to
+	// HTML from a trusted source.
+	CaptionHTML template.HTML
+	// If true, header cells are spanned: adjacent cells in a header row with
+	// the same text are merged, as are empty cells with the cell above them.
+	// Pivot tables are always spanned.
+	SpanHeaders bool
+	// How each column's body cells are rendered, by column key; columns
+	// without a mode are text, which is escaped.  Markdown and HTML cells are
+	// sanitized so that they can't inject script.
+	ContentModes map[string]ContentMode
+	// The Sanitizer for HTML and Markdown cells; if nil, the DefaultSanitizer
+	// is used.
+	Sanitizer *Sanitizer
+	// Links for columns' cells, by column key, e.g. to link an issue number
+	// to its tracker.
+	Links map[string]Link
+	// The rel and target attributes of links that don't set their own,
+	// including auto-linked URLs, e.g. "nofollow" and "_blank".  Links with a
+	// target get noopener.
+	LinkRel    string
+	LinkTarget string
+	// Renderers for columns' cells, by column key, e.g. an Image, Badge, or
+	// BoolIcon; a column with a Renderer ignores its Formatter and
+	// ContentMode.
+	Renderers map[string]Renderer
+	// Conditional formatting rules, which add classes and styles to the body
+	// cells and rows that match them.
+	Rules []Rule
+	// Color scales that shade numeric columns' cells by value, by column
+	// key, e.g. for a heatmap.
+	ColorScales map[string]ColorScale
+	// Data bars drawn in numeric columns' cells, by column key.
+	DataBars map[string]DataBar
+	// Sparklines, each of which adds a column of inline SVG charts.
+	Sparklines []Sparkline
+	// A chart of the table's data, which is written before the table.
+	Chart *Chart
+	// The Theme that adds classes to the table's parts, e.g. one of the
+	// Themes.  If IncludeCSS is set, its stylesheet, if it has one, is
+	// written before the table in a style element with the StyleNonce.
+	Theme      *Theme
+	IncludeCSS bool
+	StyleNonce string // CSP nonce for the inline stylesheet
+	groups     []group
+	total      []string
+	footer     []footRow
+	formats    map[int]Formatter   // the Formats, by output column
+	modes      map[int]ContentMode // the ContentModes, by output column
+	links      map[int]*Link       // the Links, by output column
+	renderers  map[int]Renderer    // the Renderers, by output column
+	rules      []rule
+	scales     map[int]*scale     // the ColorScales, by output column
+	bars       map[int]*bar       // the DataBars, by output column
+	sparks     map[int]*sparkline // the Sparklines, by output column
+	chart      template.HTML      // the Chart and its link to the table
+	types      []ColumnType
+	// The pivot's column totals, if there are any, and their aggregate.
+	pivotTotal []string
+	pivotAgg   Aggregate
+	rowHeads   int     // the number of row header columns, if transposed
+	mergeIdx   []int   // the MergeColumns, by output column
+	cellCols   [][]int // the column that each of the Rows' cells starts at
+	cols       []template.HTMLAttr
+	colHeads   [][]string // the IDs of each column's header cells
 	tpl        *template.Template
 }
 
@@ -141,20 +409,55 @@ type HTMLTable struct {
 // not the case, the table header information must be explicitly set.
 func New(n string) *HTMLTable {
 	funcMap := template.FuncMap{
-		"htag": Heading,
+		"heading":     (*HTMLTable).heading,
+		"caption":     (*HTMLTable).caption,
+		"headrows":    (*HTMLTable).headerGrid,
+		"groups":      (*HTMLTable).bodyGroups,
+		"footrows":    (*HTMLTable).footRows,
+		"rowhead":     (*HTMLTable).isRowHeader,
+		"bodyrows":    (*HTMLTable).bodyRows,
+		"colgroup":    (*HTMLTable).colGroupAttrs,
+		"descid":      (*HTMLTable).descriptionID,
+		"describedby": (*HTMLTable).describedBy,
+		"chart":       (*HTMLTable).chartHTML,
+		"css":         (*HTMLTable).css,
+		"tableclass":  (*HTMLTable).tableClass,
+		"class":       (*HTMLTable).classAttr,
 	}
-
-	return &HTMLTable{Class: n, HasHeader: true, HeaderRowNum: 1, tpl: template.Must(template.New(n).Funcs(funcMap).Parse(tableTpl))}
+	tpl := template.Must(template.New(n).Funcs(funcMap).Parse(tableTpl))
+	template.Must(tpl.Parse(sortTpl))
+	template.Must(tpl.Parse(filterTpl))
+	return &HTMLTable{Class: n, HasHeader: true, HeaderRowNum: 1, sortCol: -1, tpl: tpl}
 }
 
 // Write accepts an io.Writer, validates the current configuration, and
 // executes the HTML table template, writing the output to the received
 // io.Writer.
 func (h *HTMLTable) Write(w io.Writer) error {
-	// Return an error if there's no table data.
-	if len(h.CSV) == 0 {
-		return errNoData
+	err := h.validate()
+	if err != nil {
+		return err
+	}
+	err = h.process()
+	if err != nil {
+		return err
+	}
+	return h.render(w)
+}
+
+// validate returns an error if the table's configuration can't be rendered
+// as HTML.  It's checked before the table is processed so that a bad
+// configuration fails without consuming or transforming any records.
+func (h *HTMLTable) validate() error {
+	if (h.Filterable || h.ColumnFilters) && h.ID == "" {
+		return errNoTableID
 	}
+	return nil
+}
+
+// render executes the HTML table template using the table's current, already
+// processed, data.
+func (h *HTMLTable) render(w io.Writer) error {
 	// If this is not empty, set it to 1, regardless of what it was set to.  This
 	// is always set to explicitly indicate that this is a non-layout table. The
 	// value must be either "" or "1".
@@ -162,9 +465,70 @@ func (h *HTMLTable) Write(w io.Writer) error {
 	if h.Border != "" {
 		h.Border = "1"
 	}
+	err := h.setMergeIdx()
+	if err != nil {
+		return err
+	}
+	err = h.groupRecords()
+	if err != nil {
+		return err
+	}
+	err = h.footerRows()
+	if err != nil {
+		return err
+	}
+	err = h.colGroup()
+	if err != nil {
+		return err
+	}
+	err = h.setContentModes()
+	if err != nil {
+		return err
+	}
+	err = h.setLinks()
+	if err != nil {
+		return err
+	}
+	err = h.setRenderers()
+	if err != nil {
+		return err
+	}
+	err = h.setRules()
+	if err != nil {
+		return err
+	}
+	err = h.setScales()
+	if err != nil {
+		return err
+	}
+	err = h.setChart()
+	if err != nil {
+		return err
+	}
+	h.colHeads = nil
+	if h.associates() {
+		h.colHeads = h.columnHeaderIDs()
+	}
+	return h.tpl.Execute(w, h)
+}
 
+// process validates the table data and separates the header rows from the
+// body rows.  It is shared by all of the output formats so that they are all
+// generated from the same table definition.
+func (h *HTMLTable) process() error {
+	if len(h.Rows) > 0 {
+		return h.processRows()
+	}
+	// Return an error if there's no table data.
+	if len(h.CSV) == 0 {
+		return errNoData
+	}
 	// If the CSV has header records, process them
 	if h.HeaderRowNum > 0 {
+		// There must be at least one record after the header records.
+		if h.HeaderRowNum >= len(h.CSV) {
+			return errNoData
+		}
 		// if there weren't any custom headers set, copy the header records from
 		// the CSV to the header rows
 		if len(h.HeaderRows) == 0 {
@@ -180,12 +544,54 @@ func (h *HTMLTable) Write(w io.Writer) error {
 		return errTableHeader
 	}
 	h.Cols = len(h.CSV[0])
-	return h.tpl.Execute(w, h)
+	h.names = nil
+	if len(h.HeaderRows) > 0 {
+		h.names = h.HeaderRows[0]
+	}
+	err := h.filter()
+	if err != nil {
+		return err
+	}
+	err = h.pivot()
+	if err != nil {
+		return err
+	}
+	err = h.sortRecords()
+	if err != nil {
+		return err
+	}
+	err = h.project()
+	if err != nil {
+		return err
+	}
+	if h.sortCol >= 0 {
+		h.sortCol = h.outputIndex(h.sortCol)
+	}
+	err = h.addSparklines()
+	if err != nil {
+		return err
+	}
+	err = h.setFormats()
+	if err != nil {
+		return err
+	}
+	err = h.transpose()
+	if err != nil {
+		return err
+	}
+	h.setTypes()
+	return nil
 }
 
-// Heading returns the heading element as template.HTML.  If the HeadingType
-// is < 0 || > 6, the DefaultHTag will be used.
+// Heading returns the heading element as template.HTML, with s escaped.  If
+// the HeadingType is < 0 || > 6, the DefaultHTag will be used.
 func Heading(i int, s string) template.HTML {
+	return headingElement(i, template.HTML(template.HTMLEscapeString(s)))
+}
+
+// headingElement returns the heading element with the received content,
+// which must already be safe.
+func headingElement(i int, content template.HTML) template.HTML {
 	var htag string
 	switch i {
 	case 1:
@@ -203,12 +609,56 @@ func Heading(i int, s string) template.HTML {
 	default:
 		htag = DefaultHTag
 	}
-	return template.HTML(fmt.Sprintf("<%s>%s</%s>", htag, s, htag))
+	return template.HTML(fmt.Sprintf("<%s>%s</%s>", htag, content, htag))
+}
+
+// heading returns the table's heading element: HeadingHTML, if it's set,
+// otherwise the escaped HeadingText.
+func (h *HTMLTable) heading() template.HTML {
+	if h.HeadingHTML != "" {
+		return headingElement(h.HeadingTag, h.HeadingHTML)
+	}
+	return Heading(h.HeadingTag, h.HeadingText)
+}
+
+// caption returns the content of the table's caption: CaptionHTML, if it's
+// set, otherwise the escaped Caption.
+func (h *HTMLTable) caption() template.HTML {
+	if h.CaptionHTML != "" {
+		return h.CaptionHTML
+	}
+	return template.HTML(template.HTMLEscapeString(h.Caption))
+}
+
+// headingText returns the text of the table's heading: HeadingHTML's text,
+// without its tags, if it's set, otherwise HeadingText.
+func (h *HTMLTable) headingText() string {
+	if h.HeadingHTML != "" {
+		return htmlText(h.HeadingHTML)
+	}
+	return h.HeadingText
+}
+
+// captionText returns the text of the table's caption: CaptionHTML's text,
+// without its tags, if it's set, otherwise Caption.
+func (h *HTMLTable) captionText() string {
+	if h.CaptionHTML != "" {
+		return htmlText(h.CaptionHTML)
+	}
+	return h.Caption
+}
+
+// htmlText returns the text of s, without its tags and with its character
+// references unescaped.
+func htmlText(s template.HTML) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(string(s), "")))
 }
 
 // Reset resets all of the structs settings to their defaults
 func (h *HTMLTable) Reset() {
 	h.HeadingText = ""
+	h.HeadingHTML = ""
+	h.CaptionHTML = ""
 	h.HeadingTag = 0
 	h.Border = ""
 	h.Caption = ""
@@ -224,6 +674,68 @@ func (h *HTMLTable) Reset() {
 	h.HeaderRowNum = 1
 	h.HeaderRows = h.HeaderRows[:0]
 	h.CSV = h.CSV[:0]
+	h.ColumnTypes = nil
+	h.Sortable = false
+	h.Filterable = false
+	h.ColumnFilters = false
+	h.ScriptNonce = ""
+	h.Columns = nil
+	h.Rename = nil
+	h.Where = ""
+	h.SortBy = nil
+	h.Collator = nil
+	h.names = nil
+	h.srcIdx = nil
+	h.sortCol = -1
+	h.sortDesc = false
+	h.GroupBy = nil
+	h.Subtotals = nil
+	h.FooterRows = nil
+	h.Formats = nil
+	h.groups = nil
+	h.total = nil
+	h.footer = nil
+	h.formats = nil
+	h.ContentModes = nil
+	h.Sanitizer = nil
+	h.modes = nil
+	h.Links = nil
+	h.LinkRel = ""
+	h.LinkTarget = ""
+	h.links = nil
+	h.Renderers = nil
+	h.renderers = nil
+	h.Rules = nil
+	h.rules = nil
+	h.ColorScales = nil
+	h.DataBars = nil
+	h.scales = nil
+	h.bars = nil
+	h.Sparklines = nil
+	h.sparks = nil
+	h.Chart = nil
+	h.chart = ""
+	h.Theme = nil
+	h.IncludeCSS = false
+	h.StyleNonce = ""
+	h.types = nil
+	h.Pivot = nil
+	h.SpanHeaders = false
+	h.pivotTotal = nil
+	h.pivotAgg = AggNone
+	h.Transpose = false
+	h.rowHeads = 0
+	h.MergeColumns = nil
+	h.mergeIdx = nil
+	h.Rows = nil
+	h.cellCols = nil
+	h.ColumnDefs = nil
+	h.ColumnClasses = 0
+	h.cols = nil
+	h.HeaderIDs = false
+	h.Description = ""
+	h.DescribedBy = ""
+	h.colHeads = nil
 }
 
 // IsTableHeaderErr returns whether or not the error returned was a result of
@@ -232,6 +744,12 @@ func IsTableHeaderErr(err error) bool {
 	return err.Error() == errTableHeader.Error()
 }
 
+// IsNoTableIDErr returns whether or not the error was a result of the table
+// not having an ID when one is required.
+func IsNoTableIDErr(err error) bool {
+	return err.Error() == errNoTableID.Error()
+}
+
 // IsNoDataErr returns whether or not the error was a result of no table data
 // being present.
 func IsNoDataErr(err error) bool {
@@ -3,6 +3,8 @@ package csv2htmltable
 import (
 	"bytes"
 	"errors"
+	"html/template"
+	"strings"
 	"testing"
 
 	json "github.com/mohae/unsafejson"
@@ -259,9 +261,11 @@ func TestWrite(t *testing.T) {
 			Expected: `
 <table class="people" border="">
     <thead>
-        <th>Greeting</th>
-        <th>Title</th>
-        <th>Name</th>
+        <tr>
+            <th scope="col">Greeting</th>
+            <th scope="col">Title</th>
+            <th scope="col">Name</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
@@ -292,9 +296,11 @@ func TestWrite(t *testing.T) {
 <table class="people" border="">
     <caption>This is a test.</caption>
     <thead>
-        <th>Greeting</th>
-        <th>Title</th>
-        <th>Name</th>
+        <tr>
+            <th scope="col">Greeting</th>
+            <th scope="col">Title</th>
+            <th scope="col">Name</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
@@ -326,9 +332,11 @@ func TestWrite(t *testing.T) {
 <table class="people" border="">
     <caption>This is a test.</caption>
     <thead>
-        <th>Greeting</th>
-        <th>Title</th>
-        <th>Name</th>
+        <tr>
+            <th scope="col">Greeting</th>
+            <th scope="col">Title</th>
+            <th scope="col">Name</th>
+        </tr>
     </thead>
     <tfoot>
         <tr>
@@ -363,20 +371,22 @@ func TestWrite(t *testing.T) {
 			Expected: `
 <table class="greetings" border="">
     <thead>
-        <th></th>
-        <th>Greeting</th>
-        <th>Title</th>
-        <th>Name</th>
+        <tr>
+            <th scope="col"></th>
+            <th scope="col">Greeting</th>
+            <th scope="col">Title</th>
+            <th scope="col">Name</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
-            <th>English</th>
+            <th scope="row">English</th>
             <td>Hello</td>
             <td>Mr.</td>
             <td>Bob</td>
         </tr>
         <tr>
-            <th>French</th>
+            <th scope="row">French</th>
             <td>Bonjour</td>
             <td>M.</td>
             <td>Genvieve</td>
@@ -432,6 +442,11 @@ func TestReset(t *testing.T) {
 	h.Section.Class = "abc"
 	h.Section.ID = "123"
 	h.CSV = [][]string{[]string{"a", "b", "c"}}
+	h.ColumnTypes = map[string]ColumnType{"a": TypeNumeric}
+	h.Sortable = true
+	h.Filterable = true
+	h.ColumnFilters = true
+	h.ScriptNonce = "nonce"
 	h.Reset()
 	if h.HeadingText != "" {
 		t.Errorf("got %q, wanted an empty string", h.HeadingText)
@@ -478,6 +493,21 @@ func TestReset(t *testing.T) {
 	if len(h.CSV) != 0 {
 		t.Errorf("CSV len was %d, wanted 0", len(h.CSV))
 	}
+	if h.ColumnTypes != nil {
+		t.Errorf("got %v, wanted nil", h.ColumnTypes)
+	}
+	if h.Sortable != false {
+		t.Errorf("got %t, wanted false", h.Sortable)
+	}
+	if h.Filterable != false {
+		t.Errorf("got %t, wanted false", h.Filterable)
+	}
+	if h.ColumnFilters != false {
+		t.Errorf("got %t, wanted false", h.ColumnFilters)
+	}
+	if h.ScriptNonce != "" {
+		t.Errorf("got %q, wanted an empty string", h.ScriptNonce)
+	}
 }
 
 func TestIsTableHeaderErr(t *testing.T) {
@@ -550,20 +580,22 @@ func TestHeaderHandling(t *testing.T) {
 			ExpectedHTML: `
 <table class="test" border="">
     <thead>
-        <th></th>
-        <th>Greeting</th>
-        <th>Title</th>
-        <th>Name</th>
+        <tr>
+            <th scope="col"></th>
+            <th scope="col">Greeting</th>
+            <th scope="col">Title</th>
+            <th scope="col">Name</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
-            <th>English</th>
+            <th scope="row">English</th>
             <td>Hello</td>
             <td>Mr.</td>
             <td>Bob</td>
         </tr>
         <tr>
-            <th>French</th>
+            <th scope="row">French</th>
             <td>Bonjour</td>
             <td>M.</td>
             <td>Genvieve</td>
@@ -588,24 +620,28 @@ func TestHeaderHandling(t *testing.T) {
 			ExpectedHTML: `
 <table class="test" border="">
     <thead>
-        <th>Language</th>
-        <th>Greeting</th>
-        <th>Title</th>
-        <th>Name</th>
-        <td>Langue</td>
-        <td>Salutation</td>
-        <td>Titre</td>
-        <td>Prénom</td>
+        <tr>
+            <th scope="col">Language</th>
+            <th scope="col">Greeting</th>
+            <th scope="col">Title</th>
+            <th scope="col">Name</th>
+        </tr>
+        <tr>
+            <td>Langue</td>
+            <td>Salutation</td>
+            <td>Titre</td>
+            <td>Prénom</td>
+        </tr>
     </thead>
     <tbody>
         <tr>
-            <th>English</th>
+            <th scope="row">English</th>
             <td>Hello</td>
             <td>Mr.</td>
             <td>Bob</td>
         </tr>
         <tr>
-            <th>French</th>
+            <th scope="row">French</th>
             <td>Bonjour</td>
             <td>M.</td>
             <td>Genvieve</td>
@@ -629,20 +665,22 @@ func TestHeaderHandling(t *testing.T) {
 			ExpectedHTML: `
 <table class="test" border="">
     <thead>
-        <th></th>
-        <th>Greeting</th>
-        <th>Title</th>
-        <th>Name</th>
+        <tr>
+            <th scope="col"></th>
+            <th scope="col">Greeting</th>
+            <th scope="col">Title</th>
+            <th scope="col">Name</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
-            <th>English</th>
+            <th scope="row">English</th>
             <td>Hello</td>
             <td>Mr.</td>
             <td>Bob</td>
         </tr>
         <tr>
-            <th>French</th>
+            <th scope="row">French</th>
             <td>Bonjour</td>
             <td>M.</td>
             <td>Genvieve</td>
@@ -668,24 +706,28 @@ func TestHeaderHandling(t *testing.T) {
 			ExpectedHTML: `
 <table class="test" border="">
     <thead>
-        <th>Language</th>
-        <th>Greeting</th>
-        <th>Title</th>
-        <th>Name</th>
-        <td>Langue</td>
-        <td>Salutation</td>
-        <td>Titre</td>
-        <td>Prénom</td>
+        <tr>
+            <th scope="col">Language</th>
+            <th scope="col">Greeting</th>
+            <th scope="col">Title</th>
+            <th scope="col">Name</th>
+        </tr>
+        <tr>
+            <td>Langue</td>
+            <td>Salutation</td>
+            <td>Titre</td>
+            <td>Prénom</td>
+        </tr>
     </thead>
     <tbody>
         <tr>
-            <th>English</th>
+            <th scope="row">English</th>
             <td>Hello</td>
             <td>Mr.</td>
             <td>Bob</td>
         </tr>
         <tr>
-            <th>French</th>
+            <th scope="row">French</th>
             <td>Bonjour</td>
             <td>M.</td>
             <td>Genvieve</td>
@@ -710,20 +752,22 @@ func TestHeaderHandling(t *testing.T) {
 			ExpectedHTML: `
 <table class="test" border="">
     <thead>
-        <th>Langue</th>
-        <th>Salutation</th>
-        <th>Titre</th>
-        <th>Prénom</th>
+        <tr>
+            <th scope="col">Langue</th>
+            <th scope="col">Salutation</th>
+            <th scope="col">Titre</th>
+            <th scope="col">Prénom</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
-            <th>English</th>
+            <th scope="row">English</th>
             <td>Hello</td>
             <td>Mr.</td>
             <td>Bob</td>
         </tr>
         <tr>
-            <th>French</th>
+            <th scope="row">French</th>
             <td>Bonjour</td>
             <td>M.</td>
             <td>Genvieve</td>
@@ -751,24 +795,28 @@ func TestHeaderHandling(t *testing.T) {
 			ExpectedHTML: `
 <table class="test" border="">
     <thead>
-        <th>Langue</th>
-        <th>Salutation</th>
-        <th>Titre</th>
-        <th>Prénom</th>
-        <td>Idioma</td>
-        <td>Saludo</td>
-        <td>Título</td>
-        <td>Nombre</td>
+        <tr>
+            <th scope="col">Langue</th>
+            <th scope="col">Salutation</th>
+            <th scope="col">Titre</th>
+            <th scope="col">Prénom</th>
+        </tr>
+        <tr>
+            <td>Idioma</td>
+            <td>Saludo</td>
+            <td>Título</td>
+            <td>Nombre</td>
+        </tr>
     </thead>
     <tbody>
         <tr>
-            <th>English</th>
+            <th scope="row">English</th>
             <td>Hello</td>
             <td>Mr.</td>
             <td>Bob</td>
         </tr>
         <tr>
-            <th>French</th>
+            <th scope="row">French</th>
             <td>Bonjour</td>
             <td>M.</td>
             <td>Genvieve</td>
@@ -827,9 +875,11 @@ func TestSection(t *testing.T) {
 			Expected: `
 <table class="test" border="">
     <thead>
-        <th>a</th>
-        <th>b</th>
-        <th>c</th>
+        <tr>
+            <th scope="col">a</th>
+            <th scope="col">b</th>
+            <th scope="col">c</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
@@ -853,9 +903,11 @@ func TestSection(t *testing.T) {
 <section class="sclass" id="sid">
 <table class="test" border="">
     <thead>
-        <th>a</th>
-        <th>b</th>
-        <th>c</th>
+        <tr>
+            <th scope="col">a</th>
+            <th scope="col">b</th>
+            <th scope="col">c</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
@@ -880,9 +932,11 @@ func TestSection(t *testing.T) {
 <section>
 <table class="test" border="">
     <thead>
-        <th>a</th>
-        <th>b</th>
-        <th>c</th>
+        <tr>
+            <th scope="col">a</th>
+            <th scope="col">b</th>
+            <th scope="col">c</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
@@ -907,9 +961,11 @@ func TestSection(t *testing.T) {
 <section class="sclass">
 <table class="test" border="">
     <thead>
-        <th>a</th>
-        <th>b</th>
-        <th>c</th>
+        <tr>
+            <th scope="col">a</th>
+            <th scope="col">b</th>
+            <th scope="col">c</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
@@ -934,9 +990,11 @@ func TestSection(t *testing.T) {
 <section id="sid">
 <table class="test" border="">
     <thead>
-        <th>a</th>
-        <th>b</th>
-        <th>c</th>
+        <tr>
+            <th scope="col">a</th>
+            <th scope="col">b</th>
+            <th scope="col">c</th>
+        </tr>
     </thead>
     <tbody>
         <tr>
@@ -969,3 +1027,215 @@ func TestSection(t *testing.T) {
 		}
 	}
 }
+
+func TestSortable(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.Sortable = true
+	h.ScriptNonce = "r4nd0m"
+	h.CSV = [][]string{
+		[]string{"Name", "Total"},
+		[]string{"Bob", "10"},
+		[]string{"Genvieve", "9"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	for _, s := range []string{
+		`<th scope="col" aria-sort="none" data-col="0" data-type="text" tabindex="0">Name</th>`,
+		`<th scope="col" aria-sort="none" data-col="1" data-type="numeric" tabindex="0">Total</th>`,
+		"</table>\n<script nonce=\"r4nd0m\">\n",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected output to contain %q; got %q", s, buf.String())
+		}
+	}
+	if !strings.HasSuffix(buf.String(), "</script>\n") {
+		t.Errorf("expected output to end with the script; got %q", buf.String())
+	}
+}
+
+func TestFilterable(t *testing.T) {
+	tests := []struct {
+		ID            string
+		Filterable    bool
+		ColumnFilters bool
+		Expected      string
+		ExpectedErr   string
+	}{
+		{ // 0
+			Filterable:  true,
+			ExpectedErr: "table ID required for filtering",
+		},
+		{ // 1
+			ColumnFilters: true,
+			ExpectedErr:   "table ID required for filtering",
+		},
+		{ // 2
+			ID: "people", Filterable: true,
+			Expected: `
+<input type="search" id="people-search" aria-label="Filter table" aria-controls="people">
+<table class="test" id="people" border="">
+    <thead>
+        <tr>
+            <th scope="col">Name</th>
+            <th scope="col">Title</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr>
+            <td>Bob</td>
+            <td>Mr.</td>
+        </tr>
+    </tbody>
+</table>
+<p id="people-status" role="status" aria-live="polite">1 of 1 rows</p>
+<script>
+`,
+		},
+		{ // 3
+			ID: "people", ColumnFilters: true,
+			Expected: `
+<table class="test" id="people" border="">
+    <thead>
+        <tr>
+            <th scope="col">Name</th>
+            <th scope="col">Title</th>
+        </tr>
+        <tr>
+            <td><input type="search" aria-label="Filter Name" aria-controls="people" data-col="0"></td>
+            <td><input type="search" aria-label="Filter Title" aria-controls="people" data-col="1"></td>
+        </tr>
+    </thead>
+    <tbody>
+        <tr>
+            <td>Bob</td>
+            <td>Mr.</td>
+        </tr>
+    </tbody>
+</table>
+<p id="people-status" role="status" aria-live="polite">1 of 1 rows</p>
+<script>
+`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.ID = test.ID
+		h.Filterable = test.Filterable
+		h.ColumnFilters = test.ColumnFilters
+		h.CSV = [][]string{
+			[]string{"Name", "Title"},
+			[]string{"Bob", "Mr."},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if !IsNoTableIDErr(err) {
+				t.Errorf("%d: got %q want %q", i, err, test.ExpectedErr)
+			} else if len(h.HeaderRows) != 0 || len(h.CSV) != 2 {
+				t.Errorf("%d: the table was processed before it was validated", i)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil: want %q", i, test.ExpectedErr)
+			continue
+		}
+		if !strings.HasPrefix(buf.String(), test.Expected) {
+			t.Errorf("%d got %q; want prefix %q", i, buf.String(), test.Expected)
+		}
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	const payload = `"><script>alert(1)</script><img src=x onerror=alert(1)>`
+	var buf bytes.Buffer
+	h := New("test")
+	h.HeadingText = payload
+	h.Caption = payload
+	h.Class = payload
+	h.ID = payload
+	h.Footer = payload
+	h.Description = payload
+	h.DescribedBy = payload
+	h.ScriptNonce = payload
+	h.Section.Include = true
+	h.Section.Class = payload
+	h.Section.ID = payload
+	h.Sortable = true
+	h.Filterable = true
+	h.ColumnFilters = true
+	h.HeaderIDs = true
+	h.HasRowHeader = true
+	h.CSV = [][]string{
+		[]string{payload, "Score"},
+		[]string{payload, payload},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	out := buf.String()
+	for _, s := range []string{"<script>alert", "<img", `"><script`, `"><img`} {
+		if strings.Contains(out, s) {
+			t.Errorf("expected output to not contain %q; got %q", s, out)
+		}
+	}
+	// The only script elements are the table's own.
+	if n := strings.Count(out, "<script"); n != 2 {
+		t.Errorf("got %d script elements; want 2", n)
+	}
+}
+
+func TestHeadingAndCaptionHTML(t *testing.T) {
+	tests := []struct {
+		HeadingText string
+		HeadingHTML template.HTML
+		Caption     string
+		CaptionHTML template.HTML
+		Expected    string
+	}{
+		{ // 0
+			HeadingText: "Sales & <b>Returns</b>",
+			Caption:     "<abbr>Q1</abbr>",
+			Expected:    "\n<h4>Sales &amp; &lt;b&gt;Returns&lt;/b&gt;</h4>\n<table class=\"test\" border=\"\">\n    <caption>&lt;abbr&gt;Q1&lt;/abbr&gt;</caption>",
+		},
+		{ // 1
+			HeadingText: "ignored",
+			HeadingHTML: `Sales <a href="/returns">&amp; Returns</a>`,
+			CaptionHTML: `<abbr title="First quarter">Q1</abbr>`,
+			Expected:    "\n<h4>Sales <a href=\"/returns\">&amp; Returns</a></h4>\n<table class=\"test\" border=\"\">\n    <caption><abbr title=\"First quarter\">Q1</abbr></caption>",
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.HeadingText = test.HeadingText
+		h.HeadingHTML = test.HeadingHTML
+		h.Caption = test.Caption
+		h.CaptionHTML = test.CaptionHTML
+		h.CSV = [][]string{
+			[]string{"Name"},
+			[]string{"Bob"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			t.Errorf("%d: got %q: want nil", i, err)
+			continue
+		}
+		if !strings.HasPrefix(buf.String(), test.Expected) {
+			t.Errorf("%d: got %q; want prefix %q", i, buf.String(), test.Expected)
+		}
+	}
+	if got := Heading(2, "<i>x</i>"); got != "<h2>&lt;i&gt;x&lt;/i&gt;</h2>" {
+		t.Errorf("got %q; want %q", got, "<h2>&lt;i&gt;x&lt;/i&gt;</h2>")
+	}
+}
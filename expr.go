@@ -0,0 +1,539 @@
+package csv2htmltable
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ExprError is a syntax or binding error in a filter expression.  Pos is the
+// 0-based byte offset in Expr of the offending token.
+type ExprError struct {
+	Expr string
+	Pos  int
+	Msg  string
+}
+
+func (e *ExprError) Error() string {
+	return fmt.Sprintf("expression error at position %d: %s", e.Pos+1, e.Msg)
+}
+
+// Context returns the expression followed by a line with a caret that points
+// at the offending position.
+func (e *ExprError) Context() string {
+	return e.Expr + "\n" + strings.Repeat(" ", utf8.RuneCountInString(e.Expr[:e.Pos])) + "^"
+}
+
+// Expr is a parsed filter expression.  An expression is made up of
+// comparisons that are combined using && (and), || (or), and ! (not), and
+// grouped with parentheses, e.g.
+//
+//	Status == "open" && (Amount > 100 || [Due Date] < "2016-10-01")
+//
+// The operands of a comparison are column names, string literals, number
+// literals, and null.  A column name that isn't a valid identifier, e.g. one
+// containing spaces, can be enclosed in brackets.  The comparison operators
+// are ==, !=, <, <=, >, >=, =~ (matches regular expression), and !~ (doesn't
+// match regular expression); the right hand side of a regular expression
+// match must be a string literal.  A field is null when it is empty, or only
+// contains whitespace, and null can only be compared using == and !=.
+//
+// Comparisons are typed: if both values are numbers they are compared as
+// numbers, otherwise if both values are dates they are compared as dates,
+// otherwise they are compared as strings.  If one side is a number literal
+// and the other side isn't a number, or if a field being compared is null,
+// the comparison is false, except for !=, which is true.
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// ParseExpr parses the filter expression s.
+func ParseExpr(s string) (*Expr, error) {
+	p := &exprParser{src: s}
+	err := p.lex()
+	if err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf(t.pos, "unexpected %s", t)
+	}
+	return &Expr{src: s, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Predicate returns a function that reports whether a record matches the
+// expression.  Column names are resolved against header, the names of the
+// record's columns.
+func (e *Expr) Predicate(header []string) (func(record []string) bool, error) {
+	return e.bind(func(name string) int {
+		for i, v := range header {
+			if v == name {
+				return i
+			}
+		}
+		return -1
+	})
+}
+
+// bind returns the expression's predicate, using col to resolve column names
+// to their index.
+func (e *Expr) bind(col func(name string) int) (func(record []string) bool, error) {
+	return e.root.bind(e, col)
+}
+
+// CompileWhere parses the filter expression s and returns its predicate for
+// records whose columns are named by header.
+func CompileWhere(s string, header []string) (func(record []string) bool, error) {
+	e, err := ParseExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	return e.Predicate(header)
+}
+
+// filter removes the records that don't match the Where expression.
+func (h *HTMLTable) filter() error {
+	match, err := h.wherePredicate()
+	if err != nil || match == nil {
+		return err
+	}
+	records := make([][]string, 0, len(h.CSV))
+	for _, rec := range h.CSV {
+		if match(rec) {
+			records = append(records, rec)
+		}
+	}
+	h.CSV = records
+	return nil
+}
+
+// wherePredicate returns the predicate for the Where expression, resolving
+// column names in the same way as the other column keys.  If there isn't a
+// Where expression, nil is returned.
+func (h *HTMLTable) wherePredicate() (func(record []string) bool, error) {
+	if strings.TrimSpace(h.Where) == "" {
+		return nil, nil
+	}
+	e, err := ParseExpr(h.Where)
+	if err != nil {
+		return nil, err
+	}
+	return e.bind(h.columnIndex)
+}
+
+// IsExprErr returns whether or not the error was a result of an invalid
+// filter expression.
+func IsExprErr(err error) bool {
+	_, ok := err.(*ExprError)
+	return ok
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokNull
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+	tokEq
+	tokNe
+	tokLt
+	tokLe
+	tokGt
+	tokGe
+	tokMatch
+	tokNotMatch
+)
+
+var operators = []struct {
+	s    string
+	kind tokenKind
+}{
+	// two character operators must precede their one character prefixes.
+	{"&&", tokAnd}, {"||", tokOr}, {"==", tokEq}, {"!=", tokNe}, {"<=", tokLe},
+	{">=", tokGe}, {"=~", tokMatch}, {"!~", tokNotMatch}, {"<", tokLt},
+	{">", tokGt}, {"!", tokNot}, {"(", tokLParen}, {")", tokRParen},
+}
+
+type token struct {
+	kind tokenKind
+	pos  int
+	text string // the token's source; for strings and identifiers, its value.
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type exprParser struct {
+	src  string
+	toks []token
+	i    int
+}
+
+func (p *exprParser) errorf(pos int, format string, args ...interface{}) error {
+	return &ExprError{Expr: p.src, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// lex splits the source into tokens.
+func (p *exprParser) lex() error {
+	s := p.src
+	i := 0
+Loop:
+	for i < len(s) {
+		r, w := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += w
+			continue
+		case r == '"' || r == '\'':
+			// Find the closing quote, skipping escaped characters.
+			j := i + 1
+			for ; j < len(s) && s[j] != byte(r); j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return p.errorf(i, "unterminated string")
+			}
+			lit := s[i : j+1]
+			if r == '\'' {
+				lit = `"` + strings.Replace(strings.Replace(lit[1:len(lit)-1], `\'`, `'`, -1), `"`, `\"`, -1) + `"`
+			}
+			v, err := strconv.Unquote(lit)
+			if err != nil {
+				return p.errorf(i, "invalid string %s", s[i:j+1])
+			}
+			p.toks = append(p.toks, token{kind: tokString, pos: i, text: v})
+			i = j + 1
+			continue
+		case r == '[':
+			j := strings.IndexByte(s[i:], ']')
+			if j < 0 {
+				return p.errorf(i, "unterminated column name")
+			}
+			p.toks = append(p.toks, token{kind: tokIdent, pos: i, text: s[i+1 : i+j]})
+			i += j + 1
+			continue
+		case r == '-' || r == '.' || unicode.IsDigit(r):
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || s[j] == 'e' || s[j] == 'E' || (s[j] >= '0' && s[j] <= '9') ||
+				((s[j] == '-' || s[j] == '+') && (s[j-1] == 'e' || s[j-1] == 'E'))) {
+				j++
+			}
+			_, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return p.errorf(i, "invalid number %q", s[i:j])
+			}
+			p.toks = append(p.toks, token{kind: tokNumber, pos: i, text: s[i:j]})
+			i = j
+			continue
+		case r == '_' || unicode.IsLetter(r):
+			j := i + w
+			for j < len(s) {
+				r, w := utf8.DecodeRuneInString(s[j:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				j += w
+			}
+			kind := tokIdent
+			if s[i:j] == "null" {
+				kind = tokNull
+			}
+			p.toks = append(p.toks, token{kind: kind, pos: i, text: s[i:j]})
+			i = j
+			continue
+		}
+		for _, op := range operators {
+			if strings.HasPrefix(s[i:], op.s) {
+				p.toks = append(p.toks, token{kind: op.kind, pos: i, text: op.s})
+				i += len(op.s)
+				continue Loop
+			}
+		}
+		return p.errorf(i, "unexpected character %q", r)
+	}
+	p.toks = append(p.toks, token{kind: tokEOF, pos: len(s)})
+	return nil
+}
+
+func (p *exprParser) peek() token {
+	return p.toks[p.i]
+}
+
+func (p *exprParser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = &logicalNode{or: true, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = &logicalNode{l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	switch p.peek().kind {
+	case tokNot:
+		p.next()
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{n: n}, nil
+	case tokLParen:
+		t := p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, p.errorf(p.peek().pos, "expected \")\" to close \"(\" at position %d, found %s", t.pos+1, p.peek())
+		}
+		p.next()
+		return n, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	if op.kind < tokEq {
+		return nil, p.errorf(op.pos, "expected comparison operator, found %s", op)
+	}
+	r, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	n := &cmpNode{op: op, l: l, r: r}
+	if l.kind == tokNull || r.kind == tokNull {
+		if op.kind != tokEq && op.kind != tokNe {
+			return nil, p.errorf(op.pos, "null can only be compared using == or !=")
+		}
+	}
+	if op.kind == tokMatch || op.kind == tokNotMatch {
+		if r.kind != tokString {
+			return nil, p.errorf(r.pos, "expected regular expression string, found %s", r)
+		}
+		n.re, err = regexp.Compile(r.text)
+		if err != nil {
+			return nil, p.errorf(r.pos, "invalid regular expression: %s", err)
+		}
+	}
+	return n, nil
+}
+
+func (p *exprParser) parseOperand() (token, error) {
+	t := p.next()
+	switch t.kind {
+	case tokIdent, tokString, tokNumber, tokNull:
+		return t, nil
+	}
+	return t, p.errorf(t.pos, "expected column, string, number, or null, found %s", t)
+}
+
+type exprNode interface {
+	bind(e *Expr, col func(string) int) (func([]string) bool, error)
+}
+
+type logicalNode struct {
+	or   bool
+	l, r exprNode
+}
+
+func (n *logicalNode) bind(e *Expr, col func(string) int) (func([]string) bool, error) {
+	l, err := n.l.bind(e, col)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.r.bind(e, col)
+	if err != nil {
+		return nil, err
+	}
+	if n.or {
+		return func(rec []string) bool { return l(rec) || r(rec) }, nil
+	}
+	return func(rec []string) bool { return l(rec) && r(rec) }, nil
+}
+
+type notNode struct {
+	n exprNode
+}
+
+func (n *notNode) bind(e *Expr, col func(string) int) (func([]string) bool, error) {
+	f, err := n.n.bind(e, col)
+	if err != nil {
+		return nil, err
+	}
+	return func(rec []string) bool { return !f(rec) }, nil
+}
+
+type cmpNode struct {
+	op   token
+	l, r token
+	re   *regexp.Regexp
+}
+
+func (n *cmpNode) bind(e *Expr, col func(string) int) (func([]string) bool, error) {
+	l, err := bindOperand(e, n.l, col)
+	if err != nil {
+		return nil, err
+	}
+	r, err := bindOperand(e, n.r, col)
+	if err != nil {
+		return nil, err
+	}
+	op := n.op.kind
+	switch {
+	case n.re != nil:
+		re := n.re
+		return func(rec []string) bool { return re.MatchString(l(rec)) == (op == tokMatch) }, nil
+	case n.l.kind == tokNull || n.r.kind == tokNull:
+		v := l
+		if n.l.kind == tokNull {
+			v = r
+		}
+		return func(rec []string) bool { return isNull(v(rec)) == (op == tokEq) }, nil
+	}
+	numeric := n.l.kind == tokNumber || n.r.kind == tokNumber
+	lcol, rcol := n.l.kind == tokIdent, n.r.kind == tokIdent
+	return func(rec []string) bool {
+		lv, rv := l(rec), r(rec)
+		c, ok := compareValues(lv, rv, numeric)
+		if (lcol && isNull(lv)) || (rcol && isNull(rv)) {
+			ok = false
+		}
+		if !ok {
+			return op == tokNe
+		}
+		switch op {
+		case tokEq:
+			return c == 0
+		case tokNe:
+			return c != 0
+		case tokLt:
+			return c < 0
+		case tokLe:
+			return c <= 0
+		case tokGt:
+			return c > 0
+		}
+		return c >= 0
+	}, nil
+}
+
+// bindOperand returns a function that returns the operand's value for a
+// record.  Fields that the record doesn't have are empty.
+func bindOperand(e *Expr, t token, col func(string) int) (func([]string) string, error) {
+	if t.kind != tokIdent {
+		v := t.text
+		return func([]string) string { return v }, nil
+	}
+	i := col(t.text)
+	if i < 0 {
+		return nil, &ExprError{Expr: e.src, Pos: t.pos, Msg: fmt.Sprintf("unknown column %q", t.text)}
+	}
+	return func(rec []string) string {
+		if i < len(rec) {
+			return rec[i]
+		}
+		return ""
+	}, nil
+}
+
+// isNull returns whether or not a field is null, i.e. empty or only
+// whitespace.
+func isNull(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// compareValues compares a and b as numbers, dates, or strings, in that
+// order of preference.  If numeric is true, they must be compared as numbers;
+// false is returned if they can't be.
+func compareValues(a, b string, numeric bool) (int, bool) {
+	af, aok := ParseNumber(a)
+	bf, bok := ParseNumber(b)
+	if aok && bok {
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		}
+		return 0, true
+	}
+	if numeric {
+		return 0, false
+	}
+	at, aok := ParseDate(a)
+	bt, bok := ParseDate(b)
+	if aok && bok {
+		switch {
+		case at.Before(bt):
+			return -1, true
+		case at.After(bt):
+			return 1, true
+		}
+		return 0, true
+	}
+	return strings.Compare(a, b), true
+}
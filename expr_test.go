@@ -0,0 +1,139 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExprPredicate(t *testing.T) {
+	header := []string{"Name", "Status", "Amount", "Due Date", "Note"}
+	records := [][]string{
+		[]string{"Bob", "open", "150", "2016-09-01", ""},
+		[]string{"Genvieve", "closed", "1,200", "2016-10-15", "paid"},
+		[]string{"Alice", "open", "99.5", "2016-11-30", "late"},
+		[]string{"Carol", "open", "n/a", "", ""},
+	}
+	tests := []struct {
+		expr     string
+		expected string // the names of the matching records
+	}{
+		{expr: `Status == "open"`, expected: "Bob,Alice,Carol"},
+		{expr: `Status == "open" && Amount > 100`, expected: "Bob"},
+		{expr: `Amount >= 99.5 || Name == 'Carol'`, expected: "Bob,Genvieve,Alice,Carol"},
+		{expr: `Amount > 1000`, expected: "Genvieve"},
+		{expr: `Amount != 150`, expected: "Genvieve,Alice,Carol"},
+		{expr: `!(Status == "open")`, expected: "Genvieve"},
+		{expr: `[Due Date] < "2016-10-01"`, expected: "Bob"},
+		{expr: `[Due Date] > "Oct 1, 2016"`, expected: "Genvieve,Alice"},
+		{expr: `Note == null`, expected: "Bob,Carol"},
+		{expr: `null != Note`, expected: "Genvieve,Alice"},
+		{expr: `Name =~ "^[A-C]"`, expected: "Bob,Alice,Carol"},
+		{expr: `Name !~ "e"`, expected: "Bob,Carol"},
+		{expr: `Status == "open" && (Note == "late" || Amount < 100)`, expected: "Alice"},
+		{expr: `Name < Status`, expected: "Bob,Genvieve,Alice,Carol"},
+	}
+	for i, test := range tests {
+		match, err := CompileWhere(test.expr, header)
+		if err != nil {
+			t.Errorf("%d: got %q: want nil", i, err)
+			continue
+		}
+		var names []string
+		for _, rec := range records {
+			if match(rec) {
+				names = append(names, rec[0])
+			}
+		}
+		if strings.Join(names, ",") != test.expected {
+			t.Errorf("%d: %s: got %q; want %q", i, test.expr, strings.Join(names, ","), test.expected)
+		}
+	}
+}
+
+func TestExprError(t *testing.T) {
+	tests := []struct {
+		expr    string
+		pos     int
+		msg     string
+		context string
+	}{
+		{expr: `Status == "open`, pos: 10, msg: "unterminated string"},
+		{expr: `Status = "open"`, pos: 7, msg: `unexpected character '='`},
+		{expr: `Status == "open" &&`, pos: 19, msg: "expected column, string, number, or null, found end of expression"},
+		{expr: `(Amount > 1`, pos: 11, msg: `expected ")" to close "(" at position 1, found end of expression`},
+		{expr: `Amount < null`, pos: 7, msg: "null can only be compared using == or !="},
+		{expr: `Name =~ "["`, pos: 8, msg: "invalid regular expression: error parsing regexp: missing closing ]: `[`"},
+		{expr: `Name =~ Status`, pos: 8, msg: `expected regular expression string, found "Status"`},
+		{expr: `Total > 100`, pos: 0, msg: `unknown column "Total"`, context: "Total > 100\n^"},
+		{expr: `Name == "x" Status`, pos: 12, msg: `unexpected "Status"`, context: "Name == \"x\" Status\n            ^"},
+	}
+	for i, test := range tests {
+		_, err := CompileWhere(test.expr, []string{"Name", "Status", "Amount"})
+		if err == nil {
+			t.Errorf("%d: got nil: want an error", i)
+			continue
+		}
+		if !IsExprErr(err) {
+			t.Errorf("%d: got %q; want an ExprError", i, err)
+			continue
+		}
+		e := err.(*ExprError)
+		if e.Pos != test.pos || e.Msg != test.msg {
+			t.Errorf("%d: got %d: %q; want %d: %q", i, e.Pos, e.Msg, test.pos, test.msg)
+		}
+		if test.context != "" && e.Context() != test.context {
+			t.Errorf("%d: got %q; want %q", i, e.Context(), test.context)
+		}
+	}
+}
+
+func TestWhere(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.Where = `Total > 5`
+	h.CSV = [][]string{
+		[]string{"Name", "Total"},
+		[]string{"Bob", "10"},
+		[]string{"Genvieve", "3"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	expected := `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th scope="col">Name</th>
+            <th scope="col">Total</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr>
+            <td>Bob</td>
+            <td>10</td>
+        </tr>
+    </tbody>
+</table>
+`
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+
+	// No matching records is an empty table body.
+	buf.Reset()
+	h.Reset()
+	h.Where = `Total > 50`
+	h.CSV = [][]string{
+		[]string{"Name", "Total"},
+		[]string{"Bob", "10"},
+	}
+	err = h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	if !strings.Contains(buf.String(), "    <tbody>\n    </tbody>\n") {
+		t.Errorf("got %q; want an empty tbody", buf.String())
+	}
+}
@@ -0,0 +1,84 @@
+package csv2htmltable
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Formatter formats a column's values for output.  Formatters are only
+// applied when the table is rendered; sorting, filtering, and aggregation
+// use the original values.
+type Formatter func(v string) string
+
+// NumberFormat returns a Formatter that formats numbers with the received
+// number of decimal places and comma thousands separators, preceded by the
+// prefix, e.g. "$".  Values that aren't numbers are returned as is.
+func NumberFormat(prefix string, decimals int) Formatter {
+	return func(v string) string {
+		f, ok := ParseNumber(v)
+		if !ok {
+			return v
+		}
+		s := strconv.FormatFloat(f, 'f', decimals, 64)
+		neg := strings.HasPrefix(s, "-")
+		if neg {
+			s = s[1:]
+		}
+		frac := ""
+		if i := strings.IndexByte(s, '.'); i >= 0 {
+			s, frac = s[:i], s[i:]
+		}
+		var b strings.Builder
+		if neg {
+			b.WriteByte('-')
+		}
+		b.WriteString(prefix)
+		for i, c := range s {
+			if i > 0 && (len(s)-i)%3 == 0 {
+				b.WriteByte(',')
+			}
+			b.WriteRune(c)
+		}
+		b.WriteString(frac)
+		return b.String()
+	}
+}
+
+// PercentFormat returns a Formatter that formats numbers as percentages with
+// the received number of decimal places; the values are expected to be
+// fractions, e.g. 0.25 is formatted as "25%".
+func PercentFormat(decimals int) Formatter {
+	return func(v string) string {
+		f, ok := ParseNumber(v)
+		if !ok {
+			return v
+		}
+		return strconv.FormatFloat(f*100, 'f', decimals, 64) + "%"
+	}
+}
+
+// setFormats resolves the Formats against the output columns.
+func (h *HTMLTable) setFormats() error {
+	h.formats = nil
+	if len(h.Formats) == 0 {
+		return nil
+	}
+	h.formats = make(map[int]Formatter, len(h.Formats))
+	for k, f := range h.Formats {
+		j := h.columnIndex(k)
+		if j < 0 {
+			return unknownColumnErr(k)
+		}
+		h.formats[j] = f
+	}
+	return nil
+}
+
+// formatField returns the i'th column's value formatted using the column's
+// Formatter; if it doesn't have one, the value is returned as is.
+func (h *HTMLTable) formatField(i int, v string) string {
+	if f := h.formats[i]; f != nil {
+		return f(v)
+	}
+	return v
+}
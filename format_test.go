@@ -0,0 +1,49 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		decimals int
+		v        string
+		expected string
+	}{
+		{v: "1234567", expected: "1,234,567"},
+		{v: "123", expected: "123"},
+		{decimals: 2, v: "1234.5", expected: "1,234.50"},
+		{prefix: "$", decimals: 2, v: "-1234.567", expected: "-$1,234.57"},
+		{prefix: "$", v: "$1,000", expected: "$1,000"},
+		{prefix: "$", v: "n/a", expected: "n/a"},
+	}
+	for i, test := range tests {
+		v := NumberFormat(test.prefix, test.decimals)(test.v)
+		if v != test.expected {
+			t.Errorf("%d: got %q; want %q", i, v, test.expected)
+		}
+	}
+}
+
+func TestPercentFormat(t *testing.T) {
+	if v := PercentFormat(1)("0.256"); v != "25.6%" {
+		t.Errorf("got %q; want \"25.6%%\"", v)
+	}
+}
+
+func TestFormatsUnknownColumn(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.Formats = map[string]Formatter{"Nope": NumberFormat("$", 2)}
+	h.CSV = [][]string{
+		[]string{"Name", "Amount"},
+		[]string{"Bob", "1000"},
+	}
+	err := h.Write(&buf)
+	want := `unknown column: "Nope"`
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v; want %q", err, want)
+	}
+}
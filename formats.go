@@ -0,0 +1,182 @@
+package csv2htmltable
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var errUnknownFormat = errors.New("unknown output format")
+
+// Format is an output format that a table can be written as.
+type Format int
+
+// Supported output formats.
+const (
+	FormatHTML Format = iota
+	FormatCSV
+	FormatJSON
+	FormatMarkdown
+)
+
+var formatNames = [...]string{"html", "csv", "json", "markdown"}
+
+var contentTypes = [...]string{
+	"text/html; charset=utf-8",
+	"text/csv; charset=utf-8",
+	"application/json; charset=utf-8",
+	"text/markdown; charset=utf-8",
+}
+
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+	return formatNames[f]
+}
+
+// ContentType returns the MIME type, including the charset, for the format.
+func (f Format) ContentType() string {
+	if f < 0 || int(f) >= len(contentTypes) {
+		return "application/octet-stream"
+	}
+	return contentTypes[f]
+}
+
+// ParseFormat returns the Format for the received name.  The name is case
+// insensitive; "md" is accepted as an alias for markdown.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "html", "htm":
+		return FormatHTML, nil
+	case "csv":
+		return FormatCSV, nil
+	case "json":
+		return FormatJSON, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	}
+	return 0, errUnknownFormat
+}
+
+// WriteFormat writes the table to the received io.Writer using the specified
+// format.
+func (h *HTMLTable) WriteFormat(w io.Writer, f Format) error {
+	switch f {
+	case FormatHTML:
+		return h.Write(w)
+	case FormatCSV:
+		return h.WriteCSV(w)
+	case FormatJSON:
+		return h.WriteJSON(w)
+	case FormatMarkdown:
+		return h.WriteMarkdown(w)
+	}
+	return errUnknownFormat
+}
+
+// WriteCSV writes the table's header rows, followed by its records, to the
+// received io.Writer as CSV.
+func (h *HTMLTable) WriteCSV(w io.Writer) error {
+	err := h.process()
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	err = cw.WriteAll(h.HeaderRows)
+	if err != nil {
+		return err
+	}
+	return cw.WriteAll(h.CSV)
+}
+
+// WriteJSON writes the table's records to the received io.Writer as a JSON
+// array.  Each record is written as an object whose keys are the values of
+// the first header row, in column order; fields without a corresponding
+// header use their 0-based column index as the key.  If the table doesn't
+// have any header rows, each record is written as an array of strings.
+func (h *HTMLTable) WriteJSON(w io.Writer) error {
+	err := h.process()
+	if err != nil {
+		return err
+	}
+	if len(h.HeaderRows) == 0 {
+		return json.NewEncoder(w).Encode(h.CSV)
+	}
+	keys := h.HeaderRows[0]
+	var buf []byte
+	buf = append(buf, '[')
+	for i, record := range h.CSV {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '{')
+		for j, field := range record {
+			if j > 0 {
+				buf = append(buf, ',')
+			}
+			key := strconv.Itoa(j)
+			if j < len(keys) {
+				key = keys[j]
+			}
+			b, _ := json.Marshal(key)
+			buf = append(buf, b...)
+			buf = append(buf, ':')
+			b, _ = json.Marshal(field)
+			buf = append(buf, b...)
+		}
+		buf = append(buf, '}')
+	}
+	buf = append(buf, ']', '\n')
+	_, err = w.Write(buf)
+	return err
+}
+
+// WriteMarkdown writes the table to the received io.Writer as a GitHub
+// flavored Markdown table.  Markdown tables have exactly one header row, so
+// only the first header row is used; if there aren't any header rows, the
+// header cells are left empty.
+func (h *HTMLTable) WriteMarkdown(w io.Writer) error {
+	err := h.process()
+	if err != nil {
+		return err
+	}
+	header := make([]string, h.Cols)
+	if len(h.HeaderRows) > 0 {
+		copy(header, h.HeaderRows[0])
+	}
+	var b strings.Builder
+	writeMarkdownRow(&b, header)
+	b.WriteByte('|')
+	for range header {
+		b.WriteString(" --- |")
+	}
+	b.WriteByte('\n')
+	for _, record := range h.CSV {
+		writeMarkdownRow(&b, record)
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+var markdownReplacer = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+
+func writeMarkdownRow(b *strings.Builder, fields []string) {
+	b.WriteByte('|')
+	for _, fld := range fields {
+		b.WriteByte(' ')
+		b.WriteString(markdownReplacer.Replace(fld))
+		b.WriteString(" |")
+	}
+	b.WriteByte('\n')
+}
+
+// IsUnknownFormatErr returns whether or not the error was a result of an
+// unknown output format.
+func IsUnknownFormatErr(err error) bool {
+	return err.Error() == errUnknownFormat.Error()
+}
@@ -0,0 +1,107 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteFormat(t *testing.T) {
+	tests := []struct {
+		Format       Format
+		HasHeader    bool
+		HeaderRowNum int
+		CSV          [][]string
+		Expected     string
+	}{
+		{ // 0
+			Format: FormatCSV, HasHeader: true, HeaderRowNum: 1,
+			CSV: [][]string{
+				[]string{"Name", "Note"},
+				[]string{"Bob", "a, b"},
+			},
+			Expected: "Name,Note\nBob,\"a, b\"\n",
+		},
+		{ // 1
+			Format: FormatJSON, HasHeader: true, HeaderRowNum: 1,
+			CSV: [][]string{
+				[]string{"Name", "Title"},
+				[]string{"Bob", "Mr."},
+				[]string{"Genvieve", "M."},
+			},
+			Expected: `[{"Name":"Bob","Title":"Mr."},{"Name":"Genvieve","Title":"M."}]` + "\n",
+		},
+		{ // 2
+			Format: FormatJSON, HasHeader: false, HeaderRowNum: 0,
+			CSV: [][]string{
+				[]string{"a", "b"},
+				[]string{"1", "2"},
+			},
+			Expected: `[["a","b"],["1","2"]]` + "\n",
+		},
+		{ // 3
+			Format: FormatMarkdown, HasHeader: true, HeaderRowNum: 1,
+			CSV: [][]string{
+				[]string{"Name", "Note"},
+				[]string{"Bob", "a|b\nc"},
+			},
+			Expected: "| Name | Note |\n| --- | --- |\n| Bob | a\\|b c |\n",
+		},
+		{ // 4
+			Format: FormatMarkdown, HasHeader: false, HeaderRowNum: 0,
+			CSV: [][]string{
+				[]string{"a", "b"},
+			},
+			Expected: "|  |  |\n| --- | --- |\n| a | b |\n",
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.HasHeader = test.HasHeader
+		h.HeaderRowNum = test.HeaderRowNum
+		h.CSV = test.CSV
+		err := h.WriteFormat(&buf, test.Format)
+		if err != nil {
+			t.Errorf("%d: got %q: want nil", i, err)
+			continue
+		}
+		if buf.String() != test.Expected {
+			t.Errorf("%d got %q; want %q", i, buf.String(), test.Expected)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected Format
+		err      bool
+	}{
+		{s: "html", expected: FormatHTML},
+		{s: "CSV", expected: FormatCSV},
+		{s: "json", expected: FormatJSON},
+		{s: "md", expected: FormatMarkdown},
+		{s: "markdown", expected: FormatMarkdown},
+		{s: "xml", err: true},
+	}
+	for i, test := range tests {
+		f, err := ParseFormat(test.s)
+		if err != nil {
+			if !test.err {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if !IsUnknownFormatErr(err) {
+				t.Errorf("%d: got %q: want %q", i, err, errUnknownFormat)
+			}
+			continue
+		}
+		if test.err {
+			t.Errorf("%d: got nil: want an error", i)
+			continue
+		}
+		if f != test.expected {
+			t.Errorf("%d: got %s; want %s", i, f, test.expected)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package csv2htmltable
+
+import (
+	"strings"
+)
+
+// group is a group of body rows, which is rendered as its own tbody.
+type group struct {
+	Label    string // the text of the group's header row; empty if it has none
+	Rows     [][]string
+	Subtotal []string // the group's subtotal row, if it has one
+	Start    int      // the index, among all of the body rows, of its first row
+}
+
+// SubtotalLabel and TotalLabel are the labels of the subtotal and grand
+// total rows.  The label is in the first column, unless that column is
+// aggregated.
+var (
+	SubtotalLabel = "Subtotal"
+	TotalLabel    = "Total"
+)
+
+// groupRecords partitions the records into groups by the GroupBy columns,
+// which are resolved against the output columns.  The groups are in the
+// order that they first appear in the records and each group's records keep
+// their order.  If GroupBy isn't set, all of the records are in one group,
+// without a header row; this is also the case if the body is made of Rows.
+// If Subtotals is set, each group gets a subtotal row
+// and the table gets a grand total row.
+func (h *HTMLTable) groupRecords() error {
+	h.groups = h.groups[:0]
+	h.total = nil
+	if len(h.GroupBy) == 0 || len(h.Rows) > 0 {
+		h.groups = append(h.groups, group{Rows: h.CSV})
+		return nil
+	}
+	idx := make([]int, len(h.GroupBy))
+	for i, k := range h.GroupBy {
+		idx[i] = h.columnIndex(k)
+		if idx[i] < 0 {
+			return unknownColumnErr(k)
+		}
+	}
+	byKey := map[string]int{}
+	for _, rec := range h.CSV {
+		vals := make([]string, len(idx))
+		for i, col := range idx {
+			if col < len(rec) {
+				vals[i] = rec[col]
+			}
+		}
+		key := strings.Join(vals, "\x00")
+		n, ok := byKey[key]
+		if !ok {
+			n = len(h.groups)
+			byKey[key] = n
+			h.groups = append(h.groups, group{Label: h.groupLabel(idx, vals)})
+		}
+		h.groups[n].Rows = append(h.groups[n].Rows, rec)
+	}
+	for i := 1; i < len(h.groups); i++ {
+		h.groups[i].Start = h.groups[i-1].Start + len(h.groups[i-1].Rows)
+	}
+	if len(h.Subtotals) == 0 {
+		return nil
+	}
+	var err error
+	for i := range h.groups {
+		h.groups[i].Subtotal, err = h.aggregateRow(h.Subtotals, h.groups[i].Rows, SubtotalLabel)
+		if err != nil {
+			return err
+		}
+	}
+	h.total, err = h.aggregateRow(h.Subtotals, h.CSV, TotalLabel)
+	return err
+}
+
+// groupLabel returns the label of the group whose GroupBy columns, idx, have
+// the received values, e.g. "Region: East, Year: 2016".
+func (h *HTMLTable) groupLabel(idx []int, vals []string) string {
+	parts := make([]string, len(vals))
+	for i, v := range vals {
+		if len(h.HeaderRows) > 0 && idx[i] < len(h.HeaderRows[0]) && h.HeaderRows[0][idx[i]] != "" {
+			v = h.HeaderRows[0][idx[i]] + ": " + v
+		}
+		parts[i] = v
+	}
+	return strings.Join(parts, ", ")
+}
+
+// bodyGroups returns the groups that the table body is made of.  There is
+// always at least one group.
+func (h *HTMLTable) bodyGroups() []group {
+	if len(h.groups) == 0 {
+		return []group{{Rows: h.CSV}}
+	}
+	return h.groups
+}
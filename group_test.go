@@ -0,0 +1,164 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGroupBy(t *testing.T) {
+	tests := []struct {
+		GroupBy      []string
+		Subtotals    map[string]Aggregate
+		HasRowHeader bool
+		Expected     string
+		ExpectedErr  string
+	}{
+		{ // 0
+			GroupBy: []string{"Region"},
+			Expected: `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th scope="col">Region</th>
+            <th scope="col">Rep</th>
+            <th scope="col">Revenue</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr class="group">
+            <th colspan="3" scope="rowgroup">Region: East</th>
+        </tr>
+        <tr>
+            <td>East</td>
+            <td>Bob</td>
+            <td>10</td>
+        </tr>
+        <tr>
+            <td>East</td>
+            <td>Carl</td>
+            <td>5.5</td>
+        </tr>
+    </tbody>
+    <tbody>
+        <tr class="group">
+            <th colspan="3" scope="rowgroup">Region: West</th>
+        </tr>
+        <tr>
+            <td>West</td>
+            <td>Alice</td>
+            <td>20</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 1
+			GroupBy:      []string{"Region", "1"},
+			Subtotals:    map[string]Aggregate{"Revenue": AggSum},
+			HasRowHeader: true,
+			Expected: `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th scope="col">Region</th>
+            <th scope="col">Rep</th>
+            <th scope="col">Revenue</th>
+        </tr>
+    </thead>
+    <tfoot>
+        <tr class="total">
+            <th scope="row">Total</th>
+            <td></td>
+            <td>35.5</td>
+        </tr>
+    </tfoot>
+    <tbody>
+        <tr class="group">
+            <th colspan="3" scope="rowgroup">Region: East, Rep: Bob</th>
+        </tr>
+        <tr>
+            <th scope="row">East</th>
+            <td>Bob</td>
+            <td>10</td>
+        </tr>
+        <tr class="subtotal">
+            <th scope="row">Subtotal</th>
+            <td></td>
+            <td>10</td>
+        </tr>
+    </tbody>
+    <tbody>
+        <tr class="group">
+            <th colspan="3" scope="rowgroup">Region: West, Rep: Alice</th>
+        </tr>
+        <tr>
+            <th scope="row">West</th>
+            <td>Alice</td>
+            <td>20</td>
+        </tr>
+        <tr class="subtotal">
+            <th scope="row">Subtotal</th>
+            <td></td>
+            <td>20</td>
+        </tr>
+    </tbody>
+    <tbody>
+        <tr class="group">
+            <th colspan="3" scope="rowgroup">Region: East, Rep: Carl</th>
+        </tr>
+        <tr>
+            <th scope="row">East</th>
+            <td>Carl</td>
+            <td>5.5</td>
+        </tr>
+        <tr class="subtotal">
+            <th scope="row">Subtotal</th>
+            <td></td>
+            <td>5.5</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 2
+			GroupBy:     []string{"Country"},
+			ExpectedErr: `unknown column: "Country"`,
+		},
+		{ // 3
+			GroupBy:     []string{"Region"},
+			Subtotals:   map[string]Aggregate{"Total": AggSum},
+			ExpectedErr: `unknown column: "Total"`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.GroupBy = test.GroupBy
+		h.Subtotals = test.Subtotals
+		h.HasRowHeader = test.HasRowHeader
+		h.CSV = [][]string{
+			[]string{"Region", "Rep", "Revenue"},
+			[]string{"East", "Bob", "10"},
+			[]string{"West", "Alice", "20"},
+			[]string{"East", "Carl", "5.5"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil: want %q", i, test.ExpectedErr)
+			continue
+		}
+		if buf.String() != test.Expected {
+			t.Errorf("%d got %q; want %q", i, buf.String(), test.Expected)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package csv2htmltable
+
+import (
+	"html/template"
+	"strconv"
+)
+
+// headerCell is a cell of a header row as it is rendered.
+type headerCell struct {
+	Text   string
+	Header bool // if true, the cell is a th, otherwise it's a td
+	Attrs  template.HTMLAttr
+	id     string // the cell's ID, if the cells are associated by ID
+	col    int    // the first column that the cell spans
+	span   int    // the number of columns that the cell spans
+	rows   int    // the number of rows that the cell spans
+}
+
+// headerGrid returns the header rows as they are rendered.  Unless headers
+// are spanned, only the cells of the first header row are th cells.  When
+// headers are spanned, adjacent cells with the same text, whose cells in the
+// rows above are also the same, are merged into one cell with a colspan and a
+// cell is extended, with a rowspan, over the empty cells below it.  Cells
+// that are covered by another cell are omitted.
+func (h *HTMLTable) headerGrid() [][]headerCell {
+	spanning := h.spansHeaders()
+	grid := make([][]headerCell, len(h.HeaderRows))
+	// covered holds the cells that are spanned by a cell in an earlier row.
+	covered := make(map[[2]int]bool)
+	for i, row := range h.HeaderRows {
+		for j := 0; j < len(row); j++ {
+			if covered[[2]int{i, j}] {
+				continue
+			}
+			c := headerCell{Text: row[j], Header: i == 0 || spanning, col: j, span: 1, rows: 1}
+			if spanning {
+				for j+c.span < len(row) && h.sameHeader(i, j, j+c.span) {
+					c.span++
+				}
+				if c.span == 1 && row[j] != "" {
+					for i+c.rows < len(h.HeaderRows) && h.emptyHeader(i+c.rows, j) {
+						covered[[2]int{i + c.rows, j}] = true
+						c.rows++
+					}
+				}
+			}
+			grid[i] = append(grid[i], c)
+			j += c.span - 1
+		}
+	}
+	for i := range grid {
+		for k, c := range grid[i] {
+			if !c.Header {
+				continue
+			}
+			sort := i == 0
+			if spanning {
+				sort = c.span == 1 && h.sortRow(grid, c.col) == i
+			}
+			if h.associates() {
+				grid[i][k].id = h.headerID(i, c.col)
+				if sort {
+					grid[i][k].id = h.columnID(c.col)
+				}
+			}
+			grid[i][k].Attrs = h.headerAttrs(grid[i][k], sort)
+		}
+	}
+	return grid
+}
+
+// spansHeaders returns whether the header cells are spanned.
+func (h *HTMLTable) spansHeaders() bool {
+	return h.SpanHeaders || h.Pivot != nil
+}
+
+// sameHeader returns whether the j'th and k'th cells of the i'th header row
+// can be merged: they aren't empty and they, and their cells in each of the
+// header rows above them, have the same text.
+func (h *HTMLTable) sameHeader(i, j, k int) bool {
+	if h.HeaderRows[i][j] == "" {
+		return false
+	}
+	for r := i; r >= 0; r-- {
+		row := h.HeaderRows[r]
+		if k >= len(row) || row[j] != row[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// emptyHeader returns whether the j'th cell of the i'th header row is empty.
+func (h *HTMLTable) emptyHeader(i, j int) bool {
+	return j < len(h.HeaderRows[i]) && h.HeaderRows[i][j] == ""
+}
+
+// sortRow returns the header row that holds the j'th column's own header,
+// i.e. the lowest cell in the column that doesn't span other columns.  The
+// sort attributes go on that cell.  If the column doesn't have its own cell,
+// -1 is returned.
+func (h *HTMLTable) sortRow(grid [][]headerCell, j int) int {
+	for i := len(grid) - 1; i >= 0; i-- {
+		for _, c := range grid[i] {
+			if j < c.col || j >= c.col+c.span {
+				continue
+			}
+			if c.span > 1 {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// headerAttrs returns the attributes of a header cell: its spans, its scope,
+// which is colgroup if it spans columns, its ID, if it has one, and its
+// Theme's classes.  If sort is true, the cell is its column's own header and
+// gets the sort attributes.
+func (h *HTMLTable) headerAttrs(c headerCell, sort bool) template.HTMLAttr {
+	var a attrs
+	scope := "col"
+	if c.span > 1 {
+		a = append(a, attr{"colspan", strconv.Itoa(c.span)})
+		scope = "colgroup"
+	}
+	if c.rows > 1 {
+		a = append(a, attr{"rowspan", strconv.Itoa(c.rows)})
+	}
+	a = append(a, attr{"scope", scope})
+	if c.id != "" {
+		a = append(a, attr{"id", c.id})
+	}
+	if class := h.Theme.part("th"); class != "" {
+		a = append(a, attr{"class", class})
+	}
+	if !sort {
+		return a.HTMLAttr()
+	}
+	switch {
+	case c.col == h.sortCol && h.sortDesc:
+		a = append(a, attr{"aria-sort", "descending"})
+	case c.col == h.sortCol:
+		a = append(a, attr{"aria-sort", "ascending"})
+	case h.Sortable:
+		a = append(a, attr{"aria-sort", "none"})
+	}
+	if h.Sortable {
+		a = append(a, attr{"data-col", strconv.Itoa(c.col)}, attr{"data-type", h.ColumnType(c.col).String()}, attr{"tabindex", "0"})
+	}
+	return a.HTMLAttr()
+}
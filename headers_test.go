@@ -0,0 +1,83 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSpanHeaders(t *testing.T) {
+	tests := []struct {
+		HeaderRows [][]string
+		Sortable   bool
+		Expected   string
+	}{
+		{ // 0
+			HeaderRows: [][]string{
+				[]string{"Name", "Score", "Score"},
+				[]string{"", "Home", "Away"},
+			},
+			Expected: `
+        <tr>
+            <th rowspan="2" scope="col">Name</th>
+            <th colspan="2" scope="colgroup">Score</th>
+        </tr>
+        <tr>
+            <th scope="col">Home</th>
+            <th scope="col">Away</th>
+        </tr>`,
+		},
+		{ // 1
+			HeaderRows: [][]string{
+				[]string{"Name", "Score", "Score"},
+				[]string{"", "Home", "Away"},
+			},
+			Sortable: true,
+			Expected: `
+        <tr>
+            <th rowspan="2" scope="col" aria-sort="none" data-col="0" data-type="text" tabindex="0">Name</th>
+            <th colspan="2" scope="colgroup">Score</th>
+        </tr>
+        <tr>
+            <th scope="col" aria-sort="none" data-col="1" data-type="numeric" tabindex="0">Home</th>
+            <th scope="col" aria-sort="none" data-col="2" data-type="numeric" tabindex="0">Away</th>
+        </tr>`,
+		},
+		{ // 2
+			HeaderRows: [][]string{
+				[]string{"A", "A", "B"},
+				[]string{"x", "x", "x"},
+			},
+			Expected: `
+        <tr>
+            <th colspan="2" scope="colgroup">A</th>
+            <th scope="col">B</th>
+        </tr>
+        <tr>
+            <th colspan="2" scope="colgroup">x</th>
+            <th scope="col">x</th>
+        </tr>`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.SpanHeaders = true
+		h.Sortable = test.Sortable
+		h.HeaderRowNum = 0
+		h.HeaderRows = test.HeaderRows
+		h.CSV = [][]string{
+			[]string{"Bob", "3", "1"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			t.Errorf("%d: got %q: want nil", i, err)
+			continue
+		}
+		if !strings.Contains(buf.String(), test.Expected) {
+			t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), test.Expected)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var errLinkTemplate = errors.New("invalid link template")
+
+// Link makes a column's cells links.  Cells without a value aren't linked.
+type Link struct {
+	// The URL template.  Each {key} is replaced by the value of the column
+	// identified by key, e.g. https://tracker/issue/{ID}, and {} by the cell's
+	// own value; the values are escaped for use in a URL.  The URL must be
+	// relative or use one of the Sanitizer's URL schemes, or the cell isn't
+	// linked.
+	URL string
+	// The link's rel attribute; if empty, the table's LinkRel is used.
+	Rel string
+	// The link's target attribute; if empty, the table's LinkTarget is used.
+	Target string
+	parts  []linkPart
+}
+
+// linkPart is either literal text of a URL template or, if col isn't -2, a
+// column whose value is substituted; a col of -1 is the cell's own column.
+type linkPart struct {
+	text string
+	col  int
+}
+
+// setLinks resolves the Links' URL templates against the output columns.
+func (h *HTMLTable) setLinks() error {
+	h.links = nil
+	if len(h.Links) == 0 {
+		return nil
+	}
+	h.links = make(map[int]*Link, len(h.Links))
+	for k, l := range h.Links {
+		i := h.columnIndex(k)
+		if i < 0 {
+			return unknownColumnErr(k)
+		}
+		l := l
+		var err error
+		l.parts, err = h.parseLink(l.URL)
+		if err != nil {
+			return err
+		}
+		if l.Rel == "" {
+			l.Rel = h.LinkRel
+		}
+		if l.Target == "" {
+			l.Target = h.LinkTarget
+		}
+		h.links[i] = &l
+	}
+	return nil
+}
+
+// parseLink splits a URL template into its parts.
+func (h *HTMLTable) parseLink(tpl string) ([]linkPart, error) {
+	var parts []linkPart
+	for s := tpl; s != ""; {
+		i := strings.IndexAny(s, "{}")
+		if i < 0 {
+			parts = append(parts, linkPart{text: s, col: -2})
+			break
+		}
+		if s[i] == '}' {
+			return nil, fmt.Errorf("%s: unexpected } in %q", errLinkTemplate, tpl)
+		}
+		if i > 0 {
+			parts = append(parts, linkPart{text: s[:i], col: -2})
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%s: unclosed { in %q", errLinkTemplate, tpl)
+		}
+		k := s[i+1 : i+end]
+		col := -1
+		if k != "" {
+			col = h.columnIndex(k)
+			if col < 0 {
+				return nil, unknownColumnErr(k)
+			}
+		}
+		parts = append(parts, linkPart{col: col})
+		s = s[i+end+1:]
+	}
+	return parts, nil
+}
+
+// ParseLinks parses a comma separated list of column=URL template pairs,
+// e.g. "ID=https://tracker/issue/{ID}".  Pairs containing commas can be
+// quoted as in CSV.
+func ParseLinks(s string) (map[string]Link, error) {
+	pairs, err := ParseRename(s)
+	if err != nil {
+		return nil, err
+	}
+	links := make(map[string]Link, len(pairs))
+	for k, v := range pairs {
+		links[k] = Link{URL: v}
+	}
+	return links, nil
+}
+
+// href returns the link's URL for the j'th cell of the received row and
+// whether it's allowed.
+func (l *Link) href(rec []string, j int, schemes []string) (string, bool) {
+	var b strings.Builder
+	for _, p := range l.parts {
+		switch p.col {
+		case -2:
+			b.WriteString(p.text)
+		case -1:
+			b.WriteString(escapeURLValue(field(rec, j)))
+		default:
+			b.WriteString(escapeURLValue(field(rec, p.col)))
+		}
+	}
+	u := b.String()
+	return u, allowedURL(u, schemes)
+}
+
+// escapeURLValue escapes a value so that it can be used anywhere in a URL.
+func escapeURLValue(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+// anchor returns an a element with the received href, rel, and target whose
+// content is the received HTML.  Links that open in a new browsing context
+// get noopener, so that the linked page can't access the table's page.
+func (h *HTMLTable) anchor(href, rel, target, content string) string {
+	a := attrs{{"href", href}}
+	if target != "" && target != "_self" && !strings.Contains(" "+rel+" ", " noopener ") && !strings.Contains(" "+rel+" ", " noreferrer ") {
+		rel = strings.TrimSpace(rel + " noopener")
+	}
+	if rel != "" {
+		a = append(a, attr{"rel", rel})
+	}
+	if target != "" {
+		a = append(a, attr{"target", target})
+	}
+	return "<a" + string(a.HTMLAttr()) + ">" + content + "</a>"
+}
+
+// autoLinkRE matches URLs, which start with a scheme or www., and email
+// addresses.
+var autoLinkRE = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+|\b[a-z0-9._%+-]+@[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}\b`)
+
+// autoLink escapes text and makes its URLs and email addresses links, using
+// the table's LinkRel and LinkTarget.  Trailing punctuation isn't part of a
+// URL, nor is a closing parenthesis without an opening one.
+func (h *HTMLTable) autoLink(s string) template.HTML {
+	var b strings.Builder
+	last := 0
+	for _, m := range autoLinkRE.FindAllStringIndex(s, -1) {
+		text := trimURL(s[m[0]:m[1]])
+		href := text
+		switch {
+		case strings.Contains(text, "@") && !strings.Contains(text, "/"):
+			href = "mailto:" + text
+		case strings.HasPrefix(strings.ToLower(text), "www."):
+			href = "http://" + text
+		}
+		if !allowedURL(href, h.sanitizer().URLSchemes) {
+			continue
+		}
+		b.WriteString(template.HTMLEscapeString(s[last:m[0]]))
+		b.WriteString(h.anchor(href, h.LinkRel, h.LinkTarget, template.HTMLEscapeString(text)))
+		last = m[0] + len(text)
+	}
+	b.WriteString(template.HTMLEscapeString(s[last:]))
+	return template.HTML(b.String())
+}
+
+// trimURL removes trailing punctuation from a URL.
+func trimURL(s string) string {
+	for len(s) > 0 {
+		c := s[len(s)-1]
+		if strings.IndexByte(".,;:!?", c) >= 0 || c == ')' && strings.Count(s, "(") < strings.Count(s, ")") {
+			s = s[:len(s)-1]
+			continue
+		}
+		break
+	}
+	return s
+}
+
+// IsLinkTemplateErr returns whether or not the error was a result of an
+// invalid link URL template.
+func IsLinkTemplateErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errLinkTemplate.Error())
+}
@@ -0,0 +1,158 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	tests := []struct {
+		Links       map[string]Link
+		LinkRel     string
+		LinkTarget  string
+		Expected    []string
+		ExpectedErr string
+	}{
+		{ // 0
+			Links: map[string]Link{"ID": {URL: "https://tracker/issue/{ID}"}},
+			Expected: []string{
+				`<td><a href="https://tracker/issue/42">42</a></td>`,
+				"<td></td>",
+			},
+		},
+		{ // 1
+			Links: map[string]Link{"Title": {URL: "/search?q={}&project={Project}"}},
+			Expected: []string{
+				`<td><a href="/search?q=Fix%20%26%20ship&amp;project=a%2Fb">Fix &amp; ship</a></td>`,
+			},
+		},
+		{ // 2
+			Links:      map[string]Link{"ID": {URL: "https://tracker/issue/{}", Rel: "nofollow"}},
+			LinkTarget: "_blank",
+			Expected: []string{
+				`<td><a href="https://tracker/issue/42" rel="nofollow noopener" target="_blank">42</a></td>`,
+			},
+		},
+		{ // 3
+			Links:   map[string]Link{"ID": {URL: "https://tracker/issue/{}", Target: "_top"}},
+			LinkRel: "noreferrer",
+			Expected: []string{
+				`<td><a href="https://tracker/issue/42" rel="noreferrer" target="_top">42</a></td>`,
+			},
+		},
+		{ // 4
+			Links:    map[string]Link{"ID": {URL: "javascript:alert({})"}},
+			Expected: []string{"<td>42</td>"},
+		},
+		{ // 5
+			Links:       map[string]Link{"ID": {URL: "https://tracker/{Owner}"}},
+			ExpectedErr: `unknown column: "Owner"`,
+		},
+		{ // 6
+			Links:       map[string]Link{"ID": {URL: "https://tracker/{ID"}},
+			ExpectedErr: `invalid link template: unclosed { in "https://tracker/{ID"`,
+		},
+		{ // 7
+			Links:       map[string]Link{"Owner": {URL: "/{}"}},
+			ExpectedErr: `unknown column: "Owner"`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.Links = test.Links
+		h.LinkRel = test.LinkRel
+		h.LinkTarget = test.LinkTarget
+		h.CSV = [][]string{
+			[]string{"ID", "Title", "Project"},
+			[]string{"42", "Fix & ship", "a/b"},
+			[]string{"", "Untracked", "a/b"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q; want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		for _, v := range test.Expected {
+			if !strings.Contains(buf.String(), v) {
+				t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), v)
+			}
+		}
+	}
+}
+
+func TestAutoLink(t *testing.T) {
+	tests := []struct {
+		Value    string
+		Target   string
+		Expected template.HTML
+	}{
+		{"no links <here>", "", "no links &lt;here&gt;"}, // 0
+		{"see https://example.com/a?b=1&c=2.", "", `see <a href="https://example.com/a?b=1&amp;c=2">https://example.com/a?b=1&amp;c=2</a>.`},                         // 1
+		{"(www.example.com)", "", `(<a href="http://www.example.com">www.example.com</a>)`},                                                                          // 2
+		{"https://en.wikipedia.org/wiki/Go_(language)", "", `<a href="https://en.wikipedia.org/wiki/Go_(language)">https://en.wikipedia.org/wiki/Go_(language)</a>`}, // 3
+		{"mail bob.smith+x@example.co.uk, or not@me", "", `mail <a href="mailto:bob.smith+x@example.co.uk">bob.smith+x@example.co.uk</a>, or not@me`},                // 4
+		{"javascript:alert(1)", "", "javascript:alert(1)"},                                                                                                           // 5
+		{"http://a.com", "_blank", `<a href="http://a.com" rel="noopener" target="_blank">http://a.com</a>`},                                                         // 6
+	}
+	h := New("test")
+	for i, test := range tests {
+		h.LinkTarget = test.Target
+		got := h.autoLink(test.Value)
+		if got != test.Expected {
+			t.Errorf("%d: got %q; want %q", i, got, test.Expected)
+		}
+	}
+}
+
+func TestAutoLinkContentMode(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.ContentModes = map[string]ContentMode{"Contact": ContentAutoLink}
+	h.Links = map[string]Link{"Site": {URL: "{}"}}
+	h.CSV = [][]string{
+		[]string{"Contact", "Site"},
+		[]string{"ops@example.com", "https://example.com"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+	want := `<td><a href="mailto:ops@example.com">ops@example.com</a></td>`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got %q; want it to contain %q", buf.String(), want)
+	}
+	// Values are escaped, so a value that is a URL isn't linked by {}.
+	want = "<td>https://example.com</td>"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got %q; want it to contain %q", buf.String(), want)
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	links, err := ParseLinks(`ID=https://tracker/issue/{ID}, "Title=/search?q={}&a=b,c"`)
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+	want := map[string]string{"ID": "https://tracker/issue/{ID}", "Title": "/search?q={}&a=b,c"}
+	if len(links) != len(want) {
+		t.Errorf("got %v; want %v", links, want)
+	}
+	for k, v := range want {
+		if links[k].URL != v {
+			t.Errorf("%s: got %q; want %q", k, links[k].URL, v)
+		}
+	}
+}
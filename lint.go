@@ -0,0 +1,178 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Severity is how serious a lint Finding is.
+type Severity int
+
+// Finding severities, from least to most serious.
+const (
+	SeverityInfo    Severity = iota // a suggestion
+	SeverityWarning                 // a likely accessibility problem
+	SeverityError                   // a definite problem, e.g. invalid markup
+)
+
+var severityNames = [...]string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return ""
+	}
+	return severityNames[s]
+}
+
+// Finding is a problem that Lint found with a table.
+type Finding struct {
+	Severity Severity
+	Rule     string // the rule that was broken, e.g. "missing-caption"
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.Severity, f.Rule, f.Message)
+}
+
+// The lint rules.
+const (
+	RuleRender      = "render" // the table couldn't be rendered
+	RuleCaption     = "missing-caption"
+	RuleEmptyHeader = "empty-header" // a header cell without any text
+	RuleDuplicateID = "duplicate-id"
+	RuleShortHeader = "short-header" // a header row with fewer cells than the body rows
+	RuleRaggedRows  = "ragged-rows"  // records with different numbers of fields
+	RuleLayoutTable = "layout-table" // a table without any header cells
+	RuleHeaderIDs   = "header-ids"   // a complex table without id/headers associations
+	RuleContrast    = "contrast"     // theme colors with too little contrast
+)
+
+var idPattern = regexp.MustCompile(`\sid="([^"]*)"`)
+
+// Lint checks the table, as it is configured, for accessibility and markup
+// problems and returns what it finds, most serious first.  The table isn't
+// modified: Lint processes and renders a copy of it.  If the table can't be
+// rendered, the only finding is the error.
+func (h *HTMLTable) Lint() []Finding {
+	c := *h
+	c.HeaderRows = append([][]string(nil), h.HeaderRows...)
+	c.CSV = append([][]string(nil), h.CSV...)
+	// The computed fields are reused by truncating them, so the copy needs
+	// its own.
+	c.mergeIdx, c.groups, c.footer, c.cols = nil, nil, nil, nil
+	var buf bytes.Buffer
+	err := c.Write(&buf)
+	if err != nil {
+		return []Finding{{SeverityError, RuleRender, err.Error()}}
+	}
+	var fs []Finding
+	if c.Caption == "" && c.CaptionHTML == "" {
+		fs = append(fs, Finding{SeverityWarning, RuleCaption, "the table doesn't have a caption"})
+	}
+	fs = append(fs, c.lintHeaders()...)
+	fs = append(fs, c.lintRecords()...)
+	fs = append(fs, lintIDs(buf.String())...)
+	fs = append(fs, c.lintTheme()...)
+	// Sort by severity, keeping the order within each severity.
+	sorted := make([]Finding, 0, len(fs))
+	for s := SeverityError; s >= SeverityInfo; s-- {
+		for _, f := range fs {
+			if f.Severity == s {
+				sorted = append(sorted, f)
+			}
+		}
+	}
+	return sorted
+}
+
+// lintHeaders checks the processed table's header cells.
+func (h *HTMLTable) lintHeaders() []Finding {
+	var fs []Finding
+	hasHeader := h.HasHeader && len(h.HeaderRows) > 0
+	if !hasHeader && !h.HasRowHeader && h.rowHeads == 0 && len(h.Rows) == 0 {
+		fs = append(fs, Finding{SeverityWarning, RuleLayoutTable, "the table doesn't have any header cells; tables shouldn't be used for layout"})
+	}
+	if !hasHeader {
+		return fs
+	}
+	for i, row := range h.headerGrid() {
+		if len(h.HeaderRows[i]) < h.Cols {
+			fs = append(fs, Finding{SeverityError, RuleShortHeader, fmt.Sprintf("header row %d has %d cells; the body rows have %d", i+1, len(h.HeaderRows[i]), h.Cols)})
+		}
+		for _, c := range row {
+			if c.Header && strings.TrimSpace(c.Text) == "" {
+				fs = append(fs, Finding{SeverityWarning, RuleEmptyHeader, fmt.Sprintf("header row %d, column %d is empty", i+1, c.col+1)})
+			}
+		}
+	}
+	rowHeader := h.HasRowHeader || h.rowHeads > 0 || len(h.mergeIdx) > 0
+	if len(h.HeaderRows) > 1 && rowHeader && !h.associates() {
+		fs = append(fs, Finding{SeverityInfo, RuleHeaderIDs, "the table has multiple header rows and row headers; set HeaderIDs to associate its cells with their headers"})
+	}
+	return fs
+}
+
+// lintRecords checks that the processed records all have the same number of
+// fields.
+func (h *HTMLTable) lintRecords() []Finding {
+	var fs []Finding
+	for i, rec := range h.CSV {
+		if len(rec) != h.Cols {
+			fs = append(fs, Finding{SeverityWarning, RuleRaggedRows, fmt.Sprintf("body row %d has %d cells; want %d", i+1, len(rec), h.Cols)})
+		}
+	}
+	return fs
+}
+
+// lintIDs checks the rendered table for duplicate IDs.
+func lintIDs(html string) []Finding {
+	var fs []Finding
+	seen := make(map[string]int)
+	for _, m := range idPattern.FindAllStringSubmatch(html, -1) {
+		seen[m[1]]++
+		if seen[m[1]] == 2 {
+			fs = append(fs, Finding{SeverityError, RuleDuplicateID, fmt.Sprintf("the id %q is used more than once", m[1])})
+		}
+	}
+	return fs
+}
+
+// ContrastRatio returns the WCAG contrast ratio, from 1 to 21, of two colors
+// in #rgb or #rrggbb form.  WCAG AA requires a ratio of at least 4.5 for
+// normal text.
+func ContrastRatio(fg, bg string) (float64, error) {
+	l1, err := luminance(fg)
+	if err != nil {
+		return 0, err
+	}
+	l2, err := luminance(bg)
+	if err != nil {
+		return 0, err
+	}
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05), nil
+}
+
+// luminance returns the relative luminance of a #rgb or #rrggbb color.
+func luminance(color string) (float64, error) {
+	c, err := parseColor(color)
+	if err != nil {
+		return 0, err
+	}
+	var rgb [3]float64
+	for i := range rgb {
+		v := float64(c[i]) / 255
+		if v <= 0.03928 {
+			rgb[i] = v / 12.92
+		} else {
+			rgb[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return 0.2126*rgb[0] + 0.7152*rgb[1] + 0.0722*rgb[2], nil
+}
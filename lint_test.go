@@ -0,0 +1,134 @@
+package csv2htmltable
+
+import (
+	"html/template"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		Caption      string
+		CaptionHTML  template.HTML
+		ID           string
+		HasHeader    bool
+		HasRowHeader bool
+		HeaderRows   [][]string
+		Expected     []string
+	}{
+		{ // 0
+			Caption:    "Scores",
+			HasHeader:  true,
+			HeaderRows: [][]string{[]string{"Name", "Home", "Away"}},
+			Expected:   nil,
+		},
+		{ // 1
+			HasHeader:    true,
+			HasRowHeader: true,
+			HeaderRows:   [][]string{[]string{"", "Home"}},
+			Expected: []string{
+				"error: short-header: header row 1 has 2 cells; the body rows have 3",
+				"warning: missing-caption: the table doesn't have a caption",
+				"warning: empty-header: header row 1, column 1 is empty",
+			},
+		},
+		{ // 2
+			Caption:  "Scores",
+			Expected: []string{"warning: layout-table: the table doesn't have any header cells; tables shouldn't be used for layout"},
+		},
+		{ // 3
+			Caption:      "Scores",
+			HasHeader:    true,
+			HasRowHeader: true,
+			HeaderRows: [][]string{
+				[]string{"Name", "Score", "Score"},
+				[]string{"Name", "Home", "Away"},
+			},
+			Expected: []string{"info: header-ids: the table has multiple header rows and row headers; set HeaderIDs to associate its cells with their headers"},
+		},
+		{ // 4
+			Caption:    "Scores",
+			ID:         "scores",
+			HasHeader:  true,
+			HeaderRows: [][]string{[]string{"Name", "Home", "Away"}},
+			Expected:   []string{`error: duplicate-id: the id "scores" is used more than once`},
+		},
+		{ // 5
+			CaptionHTML: "<em>Scores</em>",
+			HasHeader:   true,
+			HeaderRows:  [][]string{[]string{"Name", "Home", "Away"}},
+			Expected:    nil,
+		},
+	}
+	h := New("test")
+	for i, test := range tests {
+		h.Reset()
+		h.Caption = test.Caption
+		h.CaptionHTML = test.CaptionHTML
+		h.ID = test.ID
+		h.Section.Include = test.ID != ""
+		h.Section.ID = test.ID
+		h.HasHeader = test.HasHeader
+		h.HasRowHeader = test.HasRowHeader
+		h.HeaderRowNum = 0
+		h.HeaderRows = test.HeaderRows
+		h.CSV = [][]string{
+			[]string{"Bob", "3", "1"},
+		}
+		var got []string
+		for _, f := range h.Lint() {
+			got = append(got, f.String())
+		}
+		if !reflect.DeepEqual(got, test.Expected) {
+			t.Errorf("%d: got %q; want %q", i, got, test.Expected)
+		}
+		// Lint doesn't modify the table.
+		if len(h.CSV) != 1 || !reflect.DeepEqual(h.HeaderRows, test.HeaderRows) {
+			t.Errorf("%d: the table was modified: %q %q", i, h.HeaderRows, h.CSV)
+		}
+	}
+}
+
+func TestLintRenderErr(t *testing.T) {
+	h := New("test")
+	h.Filterable = true
+	h.CSV = [][]string{
+		[]string{"Name"},
+		[]string{"Bob"},
+	}
+	fs := h.Lint()
+	if len(fs) != 1 || fs[0].Severity != SeverityError || fs[0].Rule != RuleRender {
+		t.Errorf("got %v; want a single render error", fs)
+	}
+}
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		FG, BG      string
+		Expected    float64
+		ExpectedErr string
+	}{
+		{"#000", "#fff", 21, ""},
+		{"#ffffff", "#ffffff", 1, ""},
+		{"#777777", "#ffffff", 4.48, ""},
+		{"#fff", "white", 0, `invalid color "white": expected #rgb or #rrggbb`},
+		{"#ggg", "#fff", 0, `invalid color "#ggg": expected #rgb or #rrggbb`},
+	}
+	for i, test := range tests {
+		r, err := ContrastRatio(test.FG, test.BG)
+		if err != nil {
+			if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		if math.Abs(r-test.Expected) > 0.01 {
+			t.Errorf("%d: got %.2f; want %.2f", i, r, test.Expected)
+		}
+	}
+}
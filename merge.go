@@ -0,0 +1,49 @@
+package csv2htmltable
+
+// mergeSpans returns, for each row of the received records, the rowspan of
+// each of the MergeColumns' cells, by column: a span of 0 means that the cell
+// is covered by the cell above it.  Consecutive identical values are merged;
+// a column only merges within the spans of the MergeColumns before it.  If
+// MergeColumns isn't set, nil is returned.
+func (h *HTMLTable) mergeSpans(records [][]string) []map[int]int {
+	if len(h.mergeIdx) == 0 {
+		return nil
+	}
+	spans := make([]map[int]int, len(records))
+	for i := range spans {
+		spans[i] = make(map[int]int, len(h.mergeIdx))
+	}
+	// start holds the row that the current span of each column starts at.
+	start := make([]int, len(h.mergeIdx))
+	for i, rec := range records {
+		same := i > 0
+		for k, col := range h.mergeIdx {
+			same = same && field(rec, col) == field(records[i-1], col)
+			if same {
+				spans[start[k]][col]++
+				spans[i][col] = 0
+				continue
+			}
+			start[k] = i
+			spans[i][col] = 1
+		}
+	}
+	return spans
+}
+
+// setMergeIdx resolves the MergeColumns against the output columns.  Rows
+// aren't merged; their spans are explicit.
+func (h *HTMLTable) setMergeIdx() error {
+	h.mergeIdx = h.mergeIdx[:0]
+	if len(h.Rows) > 0 {
+		return nil
+	}
+	for _, k := range h.MergeColumns {
+		i := h.columnIndex(k)
+		if i < 0 {
+			return unknownColumnErr(k)
+		}
+		h.mergeIdx = append(h.mergeIdx, i)
+	}
+	return nil
+}
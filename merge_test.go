@@ -0,0 +1,129 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMergeColumns(t *testing.T) {
+	tests := []struct {
+		MergeColumns []string
+		GroupBy      []string
+		Expected     string
+		ExpectedErr  string
+	}{
+		{ // 0
+			MergeColumns: []string{"Region", "Year"},
+			Expected: `
+<table class="test" id="sales" border="">
+    <thead>
+        <tr>
+            <th scope="col" id="sales-c0">Region</th>
+            <th scope="col" id="sales-c1">Year</th>
+            <th scope="col" id="sales-c2">Revenue</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr>
+            <th rowspan="3" scope="row" id="sales-r0c0" headers="sales-c0">East</th>
+            <th rowspan="2" scope="row" id="sales-r0c1" headers="sales-c1 sales-r0c0">2023</th>
+            <td headers="sales-c2 sales-r0c0 sales-r0c1">10</td>
+        </tr>
+        <tr>
+            <td headers="sales-c2 sales-r0c0 sales-r0c1">12</td>
+        </tr>
+        <tr>
+            <th scope="row" id="sales-r2c1" headers="sales-c1 sales-r0c0">2024</th>
+            <td headers="sales-c2 sales-r0c0 sales-r2c1">7</td>
+        </tr>
+        <tr>
+            <th scope="row" id="sales-r3c0" headers="sales-c0">West</th>
+            <th scope="row" id="sales-r3c1" headers="sales-c1 sales-r3c0">2024</th>
+            <td headers="sales-c2 sales-r3c0 sales-r3c1">20</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 1
+			MergeColumns: []string{"Year"},
+			GroupBy:      []string{"Region"},
+			Expected: `
+<table class="test" id="sales" border="">
+    <thead>
+        <tr>
+            <th scope="col" id="sales-c0">Region</th>
+            <th scope="col" id="sales-c1">Year</th>
+            <th scope="col" id="sales-c2">Revenue</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr class="group">
+            <th colspan="3" scope="rowgroup">Region: East</th>
+        </tr>
+        <tr>
+            <td headers="sales-c0 sales-r0c1">East</td>
+            <th rowspan="2" scope="row" id="sales-r0c1" headers="sales-c1">2023</th>
+            <td headers="sales-c2 sales-r0c1">10</td>
+        </tr>
+        <tr>
+            <td headers="sales-c0 sales-r0c1">East</td>
+            <td headers="sales-c2 sales-r0c1">12</td>
+        </tr>
+        <tr>
+            <td headers="sales-c0 sales-r2c1">East</td>
+            <th scope="row" id="sales-r2c1" headers="sales-c1">2024</th>
+            <td headers="sales-c2 sales-r2c1">7</td>
+        </tr>
+    </tbody>
+    <tbody>
+        <tr class="group">
+            <th colspan="3" scope="rowgroup">Region: West</th>
+        </tr>
+        <tr>
+            <td headers="sales-c0 sales-r3c1">West</td>
+            <th scope="row" id="sales-r3c1" headers="sales-c1">2024</th>
+            <td headers="sales-c2 sales-r3c1">20</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 2
+			MergeColumns: []string{"Quarter"},
+			ExpectedErr:  `unknown column: "Quarter"`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.ID = "sales"
+		h.MergeColumns = test.MergeColumns
+		h.GroupBy = test.GroupBy
+		h.CSV = [][]string{
+			[]string{"Region", "Year", "Revenue"},
+			[]string{"East", "2023", "10"},
+			[]string{"East", "2023", "12"},
+			[]string{"East", "2024", "7"},
+			[]string{"West", "2024", "20"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil: want %q", i, test.ExpectedErr)
+			continue
+		}
+		if buf.String() != test.Expected {
+			t.Errorf("%d got %q; want %q", i, buf.String(), test.Expected)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Source provides the table that a Handler serves.  Table is called once per
+// request and must return a table that hasn't been written yet, as writing a
+// table consumes its header records.
+type Source interface {
+	Table(r *http.Request) (*HTMLTable, error)
+}
+
+// SourceFunc is an adapter that allows an ordinary function to be used as a
+// Source.
+type SourceFunc func(r *http.Request) (*HTMLTable, error)
+
+// Table calls f(r).
+func (f SourceFunc) Table(r *http.Request) (*HTMLTable, error) {
+	return f(r)
+}
+
+// Handler is an http.Handler that serves a table in the format requested by
+// the client.  The format is determined by the request's "format" query
+// parameter, if set, otherwise by its Accept header.  When the client doesn't
+// express a preference, the table is served as HTML.  An unsupported format
+// parameter is a bad request; an Accept header that none of the formats
+// satisfy is not acceptable.
+type Handler struct {
+	Source Source
+}
+
+// NewHandler returns a Handler that serves the tables provided by s.
+func NewHandler(s Source) *Handler {
+	return &Handler{Source: s}
+}
+
+// ServeHTTP implements http.Handler.
+func (hd *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+	var f Format
+	var ok bool
+	if v := r.URL.Query().Get("format"); v != "" {
+		var err error
+		f, err = ParseFormat(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%s: %q", err, v), http.StatusBadRequest)
+			return
+		}
+		ok = true
+	} else {
+		f, ok = NegotiateFormat(r.Header.Get("Accept"))
+	}
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return
+	}
+	h, err := hd.Source.Table(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Buffer the output so that an error can still be reported with the
+	// appropriate status code.
+	var buf bytes.Buffer
+	err = h.WriteFormat(&buf, f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", f.ContentType())
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(buf.Bytes())
+	}
+}
+
+// mediaTypes maps the media types that are understood to their Format.  The
+// order is used to break ties between equally preferred types.
+var mediaTypes = []struct {
+	typ string
+	f   Format
+}{
+	{"text/html", FormatHTML},
+	{"application/json", FormatJSON},
+	{"text/csv", FormatCSV},
+	{"text/markdown", FormatMarkdown},
+	{"text/x-markdown", FormatMarkdown},
+}
+
+// NegotiateFormat returns the Format that best matches the received Accept
+// header value.  An empty Accept header matches FormatHTML.  If none of the
+// supported formats are acceptable, false is returned.
+func NegotiateFormat(accept string) (Format, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return FormatHTML, true
+	}
+	var best Format
+	var bestQ float64
+	var bestSpecificity int
+	var found bool
+	for _, mt := range mediaTypes {
+		q, specificity := acceptQuality(accept, mt.typ)
+		if q <= 0 {
+			continue
+		}
+		if !found || q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity, found = mt.f, q, specificity, true
+		}
+	}
+	return best, found
+}
+
+// acceptQuality returns the quality value the Accept header assigns to the
+// received media type, using the most specific matching media range, along
+// with that range's specificity: 0 for */*, 1 for type/*, and 2 for an exact
+// match.
+func acceptQuality(accept, typ string) (q float64, specificity int) {
+	specificity = -1
+	for _, rng := range strings.Split(accept, ",") {
+		params := strings.Split(rng, ";")
+		mr := strings.ToLower(strings.TrimSpace(params[0]))
+		var s int
+		switch {
+		case mr == typ:
+			s = 2
+		case mr == "*/*":
+			s = 0
+		case strings.HasSuffix(mr, "/*") && strings.HasPrefix(typ, mr[:len(mr)-1]):
+			s = 1
+		default:
+			continue
+		}
+		if s < specificity {
+			continue
+		}
+		rq := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if len(p) > 2 && (p[0] == 'q' || p[0] == 'Q') && p[1] == '=' {
+				v, err := strconv.ParseFloat(p[2:], 64)
+				if err == nil {
+					rq = v
+				}
+			}
+		}
+		q, specificity = rq, s
+	}
+	return q, specificity
+}
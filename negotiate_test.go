@@ -0,0 +1,101 @@
+package csv2htmltable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected Format
+		ok       bool
+	}{
+		{accept: "", expected: FormatHTML, ok: true},
+		{accept: "*/*", expected: FormatHTML, ok: true},
+		{accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", expected: FormatHTML, ok: true},
+		{accept: "application/json", expected: FormatJSON, ok: true},
+		{accept: "text/csv;q=0.5, application/json;q=0.4", expected: FormatCSV, ok: true},
+		{accept: "text/*;q=0.5, text/markdown", expected: FormatMarkdown, ok: true},
+		{accept: "text/html;q=0, */*;q=0.1", expected: FormatJSON, ok: true},
+		{accept: "image/png", ok: false},
+	}
+	for i, test := range tests {
+		f, ok := NegotiateFormat(test.accept)
+		if ok != test.ok {
+			t.Errorf("%d: got %t; want %t", i, ok, test.ok)
+			continue
+		}
+		if ok && f != test.expected {
+			t.Errorf("%d: got %s; want %s", i, f, test.expected)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	src := SourceFunc(func(r *http.Request) (*HTMLTable, error) {
+		h := New("test")
+		h.CSV = [][]string{
+			[]string{"a", "b"},
+			[]string{"1", "2"},
+		}
+		return h, nil
+	})
+	tests := []struct {
+		url         string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{ // 0
+			url: "/", accept: "application/json", status: http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"a":"1","b":"2"}]` + "\n",
+		},
+		{ // 1
+			url: "/?format=csv", accept: "application/json", status: http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			body:        "a,b\n1,2\n",
+		},
+		{ // 2
+			url: "/", accept: "text/html", status: http.StatusOK,
+			contentType: "text/html; charset=utf-8",
+			body:        "\n<table class=\"test\" border=\"\">",
+		},
+		{ // 3
+			url: "/?format=xml", status: http.StatusBadRequest,
+			body: "unknown output format: \"xml\"\n",
+		},
+		{ // 4
+			url: "/", accept: "image/png", status: http.StatusNotAcceptable,
+		},
+	}
+	hd := NewHandler(src)
+	for i, test := range tests {
+		r := httptest.NewRequest("GET", test.url, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		w := httptest.NewRecorder()
+		hd.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%d: got status %d; want %d", i, w.Code, test.status)
+			continue
+		}
+		if test.status != http.StatusOK {
+			if test.body != "" && w.Body.String() != test.body {
+				t.Errorf("%d: got %q; want %q", i, w.Body.String(), test.body)
+			}
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); ct != test.contentType {
+			t.Errorf("%d: got content type %q; want %q", i, ct, test.contentType)
+		}
+		if !strings.HasPrefix(w.Body.String(), test.body) {
+			t.Errorf("%d: got %q; want prefix %q", i, w.Body.String(), test.body)
+		}
+	}
+}
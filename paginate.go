@@ -0,0 +1,352 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PageNameFormat is the format used to generate the file name of each page;
+// it is passed the 1-based page number.
+const PageNameFormat = "page-%04d.html"
+
+// PageIndexName is the file name of the page index.
+const PageIndexName = "index.html"
+
+var errPageSize = errors.New("page size must be greater than 0")
+var errStreamedPages = errors.New("streamed pages can't be aggregated")
+
+// pageTpl is the HTML document that each page, and the page index, is
+// written as.
+var pageTpl = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}{{if .Page}} (page {{.Page}} of {{.Pages}}){{end}}</title>
+</head>
+<body>
+<nav aria-label="Pagination">
+{{- if .Page}}
+    {{- if .Prev}}
+    <a href="{{.First}}" rel="first">First</a>
+    <a href="{{.Prev}}" rel="prev">Previous</a>
+    {{- else}}
+    <span aria-disabled="true">First</span>
+    <span aria-disabled="true">Previous</span>
+    {{- end}}
+    <span aria-current="page">Page {{.Page}} of {{.Pages}}</span>
+    {{- if .Next}}
+    <a href="{{.Next}}" rel="next">Next</a>
+    <a href="{{.Last}}" rel="last">Last</a>
+    {{- else}}
+    <span aria-disabled="true">Next</span>
+    <span aria-disabled="true">Last</span>
+    {{- end}}
+    <a href="{{.Index}}" rel="index">All pages</a>
+{{- else}}
+    <ol>
+    {{- range .Links}}
+        <li><a href="{{.Href}}">Page {{.Page}}</a>: rows {{.From}}&ndash;{{.To}}</li>
+    {{- end}}
+    </ol>
+{{- end}}
+</nav>
+{{- .Table -}}
+</body>
+</html>
+`))
+
+// page holds the information needed to write a page, or the page index.  The
+// page index has a Page of 0.
+type page struct {
+	Title                   string
+	Page, Pages             int
+	First, Prev, Next, Last string
+	Index                   string
+	Links                   []pageLink
+	Table                   template.HTML
+}
+
+type pageLink struct {
+	Href           string
+	Page, From, To int
+}
+
+// PageName returns the file name of the i'th page, which is 1-based.
+func PageName(i int) string {
+	return fmt.Sprintf(PageNameFormat, i)
+}
+
+// WritePages splits the table's records into pages of n records each and
+// writes each page to its own HTML document in dir, using PageNameFormat for
+// the file names.  Each page's table has the same heading, caption, header
+// rows, and footer, and is preceded by links to the first, previous, next,
+// and last pages, along with a link to the page index.  The page index,
+// which links to every page, is written to PageIndexName in dir.  Only CSV
+// records are paginated; a table whose body is made of Rows is written in
+// full on each page.
+func (h *HTMLTable) WritePages(dir string, n int) error {
+	if n <= 0 {
+		return errPageSize
+	}
+	err := h.validate()
+	if err != nil {
+		return err
+	}
+	err = h.process()
+	if err != nil {
+		return err
+	}
+	err = createPageDir(dir)
+	if err != nil {
+		return err
+	}
+	records := h.CSV
+	defer func() { h.CSV = records }()
+	pages := (len(records) + n - 1) / n
+	for i := 0; i < pages; i++ {
+		end := (i + 1) * n
+		if end > len(records) {
+			end = len(records)
+		}
+		h.CSV = records[i*n : end]
+		err = h.writePage(dir, i+1, pages)
+		if err != nil {
+			return err
+		}
+	}
+	return h.writePageIndex(dir, pages, n, len(records))
+}
+
+// WritePagesFrom is like WritePages except that the table's records are read
+// from r instead of the CSV field.  Only one page of records is held in
+// memory at a time: once the header records have been read, the remaining
+// records are spooled to a temporary file so that the number of pages is
+// known before the first page is written.  Because of this, each page is
+// processed on its own, e.g. column types are inferred from each page's
+// records and, if SortBy is set, each page's records are sorted on their own;
+// the Where expression is applied as the records are read.  Options that
+// aggregate all of the records, Pivot, Subtotals, and FooterRows, would only
+// aggregate each page's, so they result in an error.  Use WritePages to sort
+// or aggregate all of the records.  The table's HeaderRowNum, HeaderRows,
+// and CSV are restored once the pages have been written.
+func (h *HTMLTable) WritePagesFrom(r *csv.Reader, dir string, n int) error {
+	if n <= 0 {
+		return errPageSize
+	}
+	err := h.validate()
+	if err != nil {
+		return err
+	}
+	if opts := h.aggregateOptions(); len(opts) > 0 {
+		return fmt.Errorf("%s: %s", errStreamedPages, strings.Join(opts, ", "))
+	}
+	headerRowNum, headerRows, records := h.HeaderRowNum, h.HeaderRows, h.CSV
+	defer func() { h.HeaderRowNum, h.HeaderRows, h.CSV = headerRowNum, headerRows, records }()
+	// Read the header records; they are used for every page.
+	for i := 0; i < h.HeaderRowNum; i++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return errNoData
+		}
+		if err != nil {
+			return err
+		}
+		h.CSV = append(h.CSV, rec)
+	}
+	if len(h.HeaderRows) == 0 {
+		h.HeaderRows = h.CSV
+	}
+	h.CSV = nil
+	h.HeaderRowNum = 0
+
+	// Records that don't match the Where expression are filtered out as they
+	// are read so that every page, except the last, is full.
+	h.names = nil
+	h.Cols = 0
+	if len(h.HeaderRows) > 0 {
+		h.Cols = len(h.HeaderRows[0])
+	}
+	match, err := h.wherePredicate()
+	if err != nil {
+		return err
+	}
+	where := h.Where
+	h.Where = ""
+	defer func() { h.Where = where }()
+
+	spool, err := ioutil.TempFile("", "csv2htmltable")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+	var total int
+	w := csv.NewWriter(spool)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if match != nil && !match(rec) {
+			continue
+		}
+		err = w.Write(rec)
+		if err != nil {
+			return err
+		}
+		total++
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		return err
+	}
+	if total == 0 {
+		return errNoData
+	}
+	err = createPageDir(dir)
+	if err != nil {
+		return err
+	}
+	_, err = spool.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	sr := csv.NewReader(spool)
+	sr.FieldsPerRecord = -1
+	pages := (total + n - 1) / n
+	headers := h.HeaderRows
+	for i := 0; i < pages; i++ {
+		// Processing a page may transform the header rows, so each page starts
+		// with the original header rows.
+		h.HeaderRows = headers
+		h.CSV = h.CSV[:0]
+		for j := 0; j < n; j++ {
+			rec, err := sr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			h.CSV = append(h.CSV, rec)
+		}
+		err = h.process()
+		if err != nil {
+			return err
+		}
+		err = h.writePage(dir, i+1, pages)
+		if err != nil {
+			return err
+		}
+	}
+	return h.writePageIndex(dir, pages, n, total)
+}
+
+// aggregateOptions returns the names of the options that are set which
+// aggregate all of the table's records.
+func (h *HTMLTable) aggregateOptions() []string {
+	var opts []string
+	if h.Pivot != nil {
+		opts = append(opts, "Pivot")
+	}
+	if len(h.Subtotals) > 0 {
+		opts = append(opts, "Subtotals")
+	}
+	if len(h.FooterRows) > 0 {
+		opts = append(opts, "FooterRows")
+	}
+	return opts
+}
+
+// createPageDir creates the directory that pages are written to, if it
+// doesn't already exist.
+func createPageDir(dir string) error {
+	return os.MkdirAll(dir, 0755)
+}
+
+// writePage renders the table's current records as the i'th of n pages.
+func (h *HTMLTable) writePage(dir string, i, n int) error {
+	var buf bytes.Buffer
+	err := h.render(&buf)
+	if err != nil {
+		return err
+	}
+	p := page{
+		Title: h.pageTitle(),
+		Page:  i,
+		Pages: n,
+		Index: PageIndexName,
+		Table: template.HTML(buf.String()),
+	}
+	if i > 1 {
+		p.First, p.Prev = PageName(1), PageName(i-1)
+	}
+	if i < n {
+		p.Next, p.Last = PageName(i+1), PageName(n)
+	}
+	return writePageFile(filepath.Join(dir, PageName(i)), p)
+}
+
+// writePageIndex writes the index of the n pages, of size records each, that
+// hold the total records.
+func (h *HTMLTable) writePageIndex(dir string, n, size, total int) error {
+	p := page{Title: h.pageTitle()}
+	for i := 1; i <= n; i++ {
+		to := i * size
+		if to > total {
+			to = total
+		}
+		p.Links = append(p.Links, pageLink{Href: PageName(i), Page: i, From: (i-1)*size + 1, To: to})
+	}
+	return writePageFile(filepath.Join(dir, PageIndexName), p)
+}
+
+// pageTitle returns the title of the page's document: the heading's text, if
+// there is any, otherwise the caption's, falling back to the template's name.
+// The text of HeadingHTML and CaptionHTML is used without their tags.
+func (h *HTMLTable) pageTitle() string {
+	if t := h.headingText(); t != "" {
+		return t
+	}
+	if t := h.captionText(); t != "" {
+		return t
+	}
+	return h.tpl.Name()
+}
+
+func writePageFile(name string, p page) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	err = pageTpl.Execute(f, p)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// IsPageSizeErr returns whether or not the error was a result of an invalid
+// page size.
+func IsPageSizeErr(err error) bool {
+	return err.Error() == errPageSize.Error()
+}
+
+// IsStreamedPagesErr returns whether or not the error was a result of
+// aggregating options being used with WritePagesFrom.
+func IsStreamedPagesErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errStreamedPages.Error())
+}
@@ -0,0 +1,166 @@
+package csv2htmltable
+
+import (
+	"encoding/csv"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var pageCSV = "Name,Title\nBob,Mr.\nGenvieve,M.\nAlice,Ms.\n"
+
+func TestWritePages(t *testing.T) {
+	tests := []struct {
+		stream   bool
+		size     int
+		caption  template.HTML
+		expected []string // the expected content, by page
+		err      error
+	}{
+		{ // 0
+			size: 0, err: errPageSize,
+		},
+		{ // 1
+			size: 2,
+			expected: []string{
+				`<span aria-disabled="true">Previous</span>`,
+				`<a href="page-0001.html" rel="prev">Previous</a>`,
+			},
+		},
+		{ // 2
+			stream: true, size: 2,
+			expected: []string{
+				`<a href="page-0002.html" rel="next">Next</a>`,
+				`<span aria-current="page">Page 2 of 2</span>`,
+			},
+		},
+		{ // 3
+			size: 1,
+			expected: []string{
+				`<a href="page-0003.html" rel="last">Last</a>`,
+				`<title>test (page 2 of 3)</title>`,
+				`<a href="page-0001.html" rel="first">First</a>`,
+			},
+		},
+		{ // 4
+			size: 2, caption: "<em>Staff</em> &amp; titles",
+			expected: []string{`<title>Staff &amp; titles (page 1 of 2)</title>`},
+		},
+	}
+	for i, test := range tests {
+		dir, err := ioutil.TempDir("", "pages")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		h := New("test")
+		h.CaptionHTML = test.caption
+		if test.stream {
+			err = h.WritePagesFrom(csv.NewReader(strings.NewReader(pageCSV)), dir, test.size)
+		} else {
+			h.CSV, _ = csv.NewReader(strings.NewReader(pageCSV)).ReadAll()
+			err = h.WritePages(dir, test.size)
+		}
+		if err != nil {
+			if test.err == nil {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if err.Error() != test.err.Error() {
+				t.Errorf("%d: got %q: want %q", i, err, test.err)
+			}
+			continue
+		}
+		for j, s := range test.expected {
+			b, err := ioutil.ReadFile(filepath.Join(dir, PageName(j+1)))
+			if err != nil {
+				t.Errorf("%d: page %d: %s", i, j+1, err)
+				continue
+			}
+			page := string(b)
+			if !strings.Contains(page, s) {
+				t.Errorf("%d: page %d: expected %q in %q", i, j+1, s, page)
+			}
+			// Every page repeats the table header.
+			if !strings.Contains(page, `<th scope="col">Name</th>`) {
+				t.Errorf("%d: page %d: table header not found in %q", i, j+1, page)
+			}
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, PageIndexName))
+		if err != nil {
+			t.Errorf("%d: index: %s", i, err)
+			continue
+		}
+		if !strings.Contains(string(b), `<a href="page-0001.html">Page 1</a>: rows 1&ndash;`) {
+			t.Errorf("%d: index: got %q", i, string(b))
+		}
+	}
+}
+
+func TestWritePagesFromNoTableID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	h := New("test")
+	h.Filterable = true
+	r := csv.NewReader(strings.NewReader(pageCSV))
+	err = h.WritePagesFrom(r, dir, 2)
+	if err == nil || !IsNoTableIDErr(err) {
+		t.Fatalf("got %v; want a table ID required error", err)
+	}
+	// The input wasn't read.
+	rec, err := r.Read()
+	if err != nil || rec[0] != "Name" {
+		t.Errorf("got %q, %v; want the header record", rec, err)
+	}
+}
+
+func TestWritePagesFromAggregates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	h := New("test")
+	h.FooterRows = []FooterRow{{Label: "Count", Aggregates: map[string]Aggregate{"Title": AggCount}}}
+	h.Pivot = &Pivot{Rows: []string{"Name"}, Columns: []string{"Title"}, Value: "Title", Aggregate: AggCount}
+	err = h.WritePagesFrom(csv.NewReader(strings.NewReader(pageCSV)), dir, 2)
+	want := "streamed pages can't be aggregated: Pivot, FooterRows"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v; want %q", err, want)
+	}
+	if !IsStreamedPagesErr(err) {
+		t.Errorf("expected IsStreamedPagesErr to be true")
+	}
+}
+
+func TestWritePagesFromRestores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	h := New("test")
+	err = h.WritePagesFrom(csv.NewReader(strings.NewReader(pageCSV)), dir, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if h.HeaderRowNum != 1 || h.HeaderRows != nil || h.CSV != nil {
+		t.Errorf("got %d, %q, %q; want 1 and no header rows or records", h.HeaderRowNum, h.HeaderRows, h.CSV)
+	}
+	// The table can be written again.
+	err = h.WritePagesFrom(csv.NewReader(strings.NewReader(pageCSV)), dir, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, PageName(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `<th scope="col">Name</th>`) || strings.Contains(string(b), `<td>Name</td>`) {
+		t.Errorf("got %q; want the header record as the header row", string(b))
+	}
+}
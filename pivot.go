@@ -0,0 +1,228 @@
+package csv2htmltable
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Pivot turns long-format records, e.g. date, product, value, into a
+// crosstab: each distinct combination of the Rows columns' values becomes a
+// row, each distinct combination of the Columns columns' values becomes a
+// column, and each cell holds the aggregate of the Value column's values of
+// the records with that row and column.  The rows and columns are sorted by
+// their values, according to the type of each key column.
+//
+// If there is more than one Columns column, the column headers are
+// hierarchical: there is a header row for each Columns column and the
+// headers of adjacent columns with the same value are spanned.
+type Pivot struct {
+	// The columns whose values identify a row; they are the first columns of
+	// the output.
+	Rows []string
+	// The columns whose values identify a column.
+	Columns []string
+	// The column whose values are aggregated.
+	Value string
+	// The aggregate used for each cell; if AggNone, the values are summed.
+	Aggregate Aggregate
+	// If true, a column with the aggregate of each row's values is added.
+	RowTotals bool
+	// If true, a footer row with the aggregate of each column's values is
+	// added.
+	ColumnTotals bool
+}
+
+// pivotKey is the values of a row's, or a column's, key columns.
+type pivotKey []string
+
+func (k pivotKey) String() string {
+	return strings.Join(k, "\x00")
+}
+
+// pivot applies the Pivot, if there is one, to the header rows and records.
+// The columns are resolved against the columns as they are in the CSV; the
+// output's columns are the Rows columns, followed by a column for each
+// column key, whose header is its values joined by "/", and the total column.
+func (h *HTMLTable) pivot() error {
+	h.pivotTotal = nil
+	p := h.Pivot
+	if p == nil {
+		return nil
+	}
+	rowIdx, err := h.columnIndexes(p.Rows)
+	if err != nil {
+		return err
+	}
+	colIdx, err := h.columnIndexes(p.Columns)
+	if err != nil {
+		return err
+	}
+	vi := h.columnIndex(p.Value)
+	if vi < 0 {
+		return unknownColumnErr(p.Value)
+	}
+	agg := p.Aggregate
+	if agg == AggNone {
+		agg = AggSum
+	}
+	typ := h.sourceType(vi)
+
+	rowKeys, rows := h.pivotKeys(rowIdx)
+	colKeys, cols := h.pivotKeys(colIdx)
+	cells := make(map[[2]int][]string)
+	rowVals := make([][]string, len(rowKeys))
+	colVals := make([][]string, len(colKeys))
+	var all []string
+	for _, rec := range h.CSV {
+		r, c := rows[keyOf(rec, rowIdx).String()], cols[keyOf(rec, colIdx).String()]
+		v := field(rec, vi)
+		cells[[2]int{r, c}] = append(cells[[2]int{r, c}], v)
+		rowVals[r] = append(rowVals[r], v)
+		colVals[c] = append(colVals[c], v)
+		all = append(all, v)
+	}
+
+	names := make([]string, 0, len(rowIdx)+len(colKeys)+1)
+	for _, i := range rowIdx {
+		names = append(names, h.sourceName(i))
+	}
+	for _, k := range colKeys {
+		names = append(names, strings.Join(k, "/"))
+	}
+	if p.RowTotals {
+		names = append(names, TotalLabel)
+	}
+
+	// There's a header row for each level of the column keys; the headers of
+	// the row key columns, and the total column, are only in the first row so
+	// that they span all of the header rows.
+	levels := len(colIdx)
+	if levels == 0 {
+		levels = 1
+	}
+	headers := make([][]string, levels)
+	for l := range headers {
+		headers[l] = make([]string, len(names))
+		if l == 0 {
+			copy(headers[l], names[:len(rowIdx)])
+			if p.RowTotals {
+				headers[l][len(names)-1] = TotalLabel
+			}
+		}
+		for j, k := range colKeys {
+			if l < len(k) {
+				headers[l][len(rowIdx)+j] = k[l]
+			}
+		}
+	}
+
+	records := make([][]string, len(rowKeys))
+	for r, k := range rowKeys {
+		rec := make([]string, 0, len(names))
+		rec = append(rec, k...)
+		for c := range colKeys {
+			rec = append(rec, agg.apply(cells[[2]int{r, c}], typ))
+		}
+		if p.RowTotals {
+			rec = append(rec, agg.apply(rowVals[r], typ))
+		}
+		records[r] = rec
+	}
+	if p.ColumnTotals {
+		total := make([]string, len(names))
+		if len(rowIdx) > 0 {
+			total[0] = TotalLabel
+		}
+		for c := range colKeys {
+			total[len(rowIdx)+c] = agg.apply(colVals[c], typ)
+		}
+		if p.RowTotals {
+			total[len(names)-1] = agg.apply(all, typ)
+		}
+		h.pivotTotal = total
+		h.pivotAgg = agg
+	}
+	h.HeaderRows = headers
+	h.CSV = records
+	h.names = names
+	h.Cols = len(names)
+	return nil
+}
+
+// columnIndexes returns the indexes of the columns identified by keys.
+func (h *HTMLTable) columnIndexes(keys []string) ([]int, error) {
+	idx := make([]int, len(keys))
+	for i, k := range keys {
+		idx[i] = h.columnIndex(k)
+		if idx[i] < 0 {
+			return nil, unknownColumnErr(k)
+		}
+	}
+	return idx, nil
+}
+
+// sourceName returns the header of the i'th column in the CSV, or, if there
+// isn't one, its 0-based index.
+func (h *HTMLTable) sourceName(i int) string {
+	if i < len(h.names) && h.names[i] != "" {
+		return h.names[i]
+	}
+	return strconv.Itoa(i)
+}
+
+// pivotKeys returns the distinct keys, made up of the values of the columns
+// at idx, of the records in sorted order, along with the index of each key.
+func (h *HTMLTable) pivotKeys(idx []int) ([]pivotKey, map[string]int) {
+	types := make([]ColumnType, len(idx))
+	for i, col := range idx {
+		types[i] = h.sourceType(col)
+	}
+	var keys []pivotKey
+	seen := make(map[string]bool)
+	for _, rec := range h.CSV {
+		k := keyOf(rec, idx)
+		if !seen[k.String()] {
+			seen[k.String()] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.SliceStable(keys, func(a, b int) bool {
+		for i, typ := range types {
+			ka, kb := makeSortKey(keys[a][i], typ), makeSortKey(keys[b][i], typ)
+			if ka.null || kb.null {
+				if ka.null == kb.null {
+					continue
+				}
+				return kb.null
+			}
+			if c := compareSortKeys(ka, kb, typ, h.Collator); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	index := make(map[string]int, len(keys))
+	for i, k := range keys {
+		index[k.String()] = i
+	}
+	return keys, index
+}
+
+// keyOf returns the values of the record's columns at idx.
+func keyOf(rec []string, idx []int) pivotKey {
+	k := make(pivotKey, len(idx))
+	for i, col := range idx {
+		k[i] = field(rec, col)
+	}
+	return k
+}
+
+// field returns the record's i'th field, or an empty string if it doesn't
+// have one.
+func field(rec []string, i int) string {
+	if i < len(rec) {
+		return rec[i]
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPivot(t *testing.T) {
+	tests := []struct {
+		Pivot       Pivot
+		SortBy      []SortKey
+		Expected    string
+		ExpectedErr string
+	}{
+		{ // 0
+			Pivot: Pivot{Rows: []string{"Product"}, Columns: []string{"Year"}, Value: "Units"},
+			Expected: `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th scope="col">Product</th>
+            <th scope="col">2023</th>
+            <th scope="col">2024</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr>
+            <td>Gadget</td>
+            <td>3</td>
+            <td></td>
+        </tr>
+        <tr>
+            <td>Widget</td>
+            <td>6</td>
+            <td>15</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 1
+			Pivot: Pivot{Rows: []string{"Product"}, Columns: []string{"Year", "Quarter"}, Value: "Units", RowTotals: true, ColumnTotals: true},
+			Expected: `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th rowspan="2" scope="col">Product</th>
+            <th colspan="2" scope="colgroup">2023</th>
+            <th scope="col">2024</th>
+            <th rowspan="2" scope="col">Total</th>
+        </tr>
+        <tr>
+            <th scope="col">Q1</th>
+            <th scope="col">Q2</th>
+            <th scope="col">Q1</th>
+        </tr>
+    </thead>
+    <tfoot>
+        <tr class="total">
+            <td>Total</td>
+            <td>5</td>
+            <td>4</td>
+            <td>15</td>
+            <td>24</td>
+        </tr>
+    </tfoot>
+    <tbody>
+        <tr>
+            <td>Gadget</td>
+            <td>3</td>
+            <td></td>
+            <td></td>
+            <td>3</td>
+        </tr>
+        <tr>
+            <td>Widget</td>
+            <td>2</td>
+            <td>4</td>
+            <td>15</td>
+            <td>21</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 2
+			Pivot:  Pivot{Rows: []string{"Product"}, Columns: []string{"Year", "Quarter"}, Value: "Units", Aggregate: AggCount},
+			SortBy: []SortKey{{Column: "2023/Q1", Desc: true}},
+			Expected: `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th rowspan="2" scope="col">Product</th>
+            <th colspan="2" scope="colgroup">2023</th>
+            <th scope="col">2024</th>
+        </tr>
+        <tr>
+            <th scope="col" aria-sort="descending">Q1</th>
+            <th scope="col">Q2</th>
+            <th scope="col">Q1</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr>
+            <td>Gadget</td>
+            <td>1</td>
+            <td>0</td>
+            <td>0</td>
+        </tr>
+        <tr>
+            <td>Widget</td>
+            <td>1</td>
+            <td>1</td>
+            <td>2</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 3
+			Pivot:       Pivot{Rows: []string{"Product"}, Columns: []string{"Month"}, Value: "Units"},
+			ExpectedErr: `unknown column: "Month"`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		p := test.Pivot
+		h.Pivot = &p
+		h.SortBy = test.SortBy
+		h.CSV = [][]string{
+			[]string{"Year", "Quarter", "Product", "Units"},
+			[]string{"2024", "Q1", "Widget", "10"},
+			[]string{"2023", "Q2", "Widget", "4"},
+			[]string{"2023", "Q1", "Gadget", "3"},
+			[]string{"2024", "Q1", "Widget", "5"},
+			[]string{"2023", "Q1", "Widget", "2"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil: want %q", i, test.ExpectedErr)
+			continue
+		}
+		if buf.String() != test.Expected {
+			t.Errorf("%d got %q; want %q", i, buf.String(), test.Expected)
+		}
+	}
+}
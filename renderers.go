@@ -0,0 +1,214 @@
+package csv2htmltable
+
+import (
+	"html/template"
+	"strconv"
+)
+
+// Renderer renders a column's cells as HTML, e.g. as an image.  Render
+// receives the cell's value and a func that returns the value of the column
+// with the received key in the cell's row.  The returned HTML is written as
+// is, so Render must escape the values that it writes.
+type Renderer interface {
+	Render(v string, field func(k string) string) template.HTML
+}
+
+// columnRefs is implemented by Renderers that use the values of other
+// columns, so that the column keys can be validated before the table is
+// rendered.
+type columnRefs interface {
+	columns() []string
+}
+
+// setRenderers resolves the Renderers, and the columns that they use,
+// against the output columns.  The columns that Sparklines add are rendered
+// by their sparklines.
+func (h *HTMLTable) setRenderers() error {
+	h.renderers = nil
+	if len(h.Renderers) == 0 && len(h.sparks) == 0 {
+		return nil
+	}
+	h.renderers = make(map[int]Renderer, len(h.Renderers)+len(h.sparks))
+	for i, s := range h.sparks {
+		h.renderers[i] = s
+	}
+	for k, r := range h.Renderers {
+		i := h.columnIndex(k)
+		if i < 0 {
+			return unknownColumnErr(k)
+		}
+		if c, ok := r.(columnRefs); ok {
+			for _, k := range c.columns() {
+				if h.columnIndex(k) < 0 {
+					return unknownColumnErr(k)
+				}
+			}
+		}
+		if h.Theme != nil {
+			// The theme's class is set on a copy so that the Renderers' values
+			// aren't modified.
+			switch b := r.(type) {
+			case BoolIcon:
+				if b.HiddenClass == "" {
+					b.HiddenClass = h.Theme.HiddenClass
+					r = b
+				}
+			case *BoolIcon:
+				if b != nil && b.HiddenClass == "" {
+					c := *b
+					c.HiddenClass = h.Theme.HiddenClass
+					r = c
+				}
+			}
+		}
+		h.renderers[i] = r
+	}
+	return nil
+}
+
+// rowField returns a func that returns the value of the column with the
+// received key in rec; if the key doesn't identify a column, it returns "".
+func (h *HTMLTable) rowField(rec []string) func(k string) string {
+	return func(k string) string {
+		i := h.columnIndex(k)
+		if i < 0 {
+			return ""
+		}
+		return field(rec, i)
+	}
+}
+
+// Image renders a column of image URLs as img elements, which are loaded
+// lazily.  URLs that aren't relative and don't use one of the
+// DefaultURLSchemes are written as text.
+type Image struct {
+	// The key of the column whose value is the image's alt text; if empty,
+	// the image is decorative and its alt text is empty.
+	Alt string
+	// The image's dimensions, in pixels; setting them avoids the table's
+	// layout shifting as images load.
+	Width, Height int
+	// The image's class.
+	Class string
+	// If true, the image is loaded immediately instead of lazily.
+	Eager bool
+}
+
+// Render implements Renderer.
+func (m Image) Render(v string, field func(k string) string) template.HTML {
+	if v == "" {
+		return ""
+	}
+	if !allowedURL(v, nil) {
+		return template.HTML(template.HTMLEscapeString(v))
+	}
+	a := attrs{{"src", v}, {"alt", ""}}
+	if m.Alt != "" {
+		a[1].value = field(m.Alt)
+	}
+	if m.Width > 0 {
+		a = append(a, attr{"width", strconv.Itoa(m.Width)})
+	}
+	if m.Height > 0 {
+		a = append(a, attr{"height", strconv.Itoa(m.Height)})
+	}
+	if m.Class != "" {
+		a = append(a, attr{"class", m.Class})
+	}
+	if !m.Eager {
+		a = append(a, attr{"loading", "lazy"})
+	}
+	return template.HTML("<img" + string(a.HTMLAttr()) + ">")
+}
+
+func (m Image) columns() []string {
+	if m.Alt == "" {
+		return nil
+	}
+	return []string{m.Alt}
+}
+
+// Badge renders a column of status values as badges: span elements with the
+// base class and the class of their value, e.g. "badge badge-ok".
+type Badge struct {
+	// The class of each value.
+	Classes map[string]string
+	// If set, the class of a value that isn't in Classes is derived from it
+	// using this prefix, e.g. "badge-" gives "in stock" "badge-in-stock"; a
+	// value without any letters or digits, e.g. "—", doesn't get a class.
+	Prefix string
+	// The class of values that aren't in Classes, if Prefix isn't set.
+	Default string
+	// The class of every badge; if empty, "badge" is used.
+	Class string
+}
+
+// Render implements Renderer.
+func (b Badge) Render(v string, field func(k string) string) template.HTML {
+	if v == "" {
+		return ""
+	}
+	class := b.Class
+	if class == "" {
+		class = "badge"
+	}
+	c, ok := b.Classes[v]
+	if !ok {
+		c = b.Default
+		if b.Prefix != "" {
+			c = ""
+			if slug := classSlug(v); slug != "" {
+				c = b.Prefix + slug
+			}
+		}
+	}
+	if c != "" {
+		class += " " + c
+	}
+	return template.HTML(`<span class="` + template.HTMLEscapeString(class) + `">` + template.HTMLEscapeString(v) + "</span>")
+}
+
+// BoolIcon renders a column of boolean values as check and cross icons.  The
+// icons are hidden from assistive technology, which reads the visually
+// hidden text that follows them instead; the text is in a span with the
+// HiddenClass, which must be hidden by the page's stylesheet.
+// Values that aren't booleans, see ParseBool, are written as text.
+type BoolIcon struct {
+	// The icons for true and false; if empty, ✓ and ✗ are used.
+	True, False string
+	// The text for true and false; if empty, "Yes" and "No" are used.
+	TrueText, FalseText string
+	// The class of every icon; if empty, "icon" is used.  The icons also get
+	// the class with a -true or -false suffix, e.g. "icon icon-true".
+	Class string
+	// The class of the visually hidden text; if empty, the table's Theme's
+	// HiddenClass is used, or, if it doesn't have one, "visually-hidden".
+	HiddenClass string
+}
+
+// Render implements Renderer.
+func (b BoolIcon) Render(v string, field func(k string) string) template.HTML {
+	t, ok := ParseBool(v)
+	if !ok {
+		return template.HTML(template.HTMLEscapeString(v))
+	}
+	class := b.Class
+	if class == "" {
+		class = "icon"
+	}
+	icon, text, suffix := orDefault(b.False, "✗"), orDefault(b.FalseText, "No"), "-false"
+	if t {
+		icon, text, suffix = orDefault(b.True, "✓"), orDefault(b.TrueText, "Yes"), "-true"
+	}
+	class += " " + class + suffix
+	return template.HTML(`<span class="` + template.HTMLEscapeString(class) + `" aria-hidden="true">` +
+		template.HTMLEscapeString(icon) + `</span><span class="` + template.HTMLEscapeString(orDefault(b.HiddenClass, "visually-hidden")) + `">` +
+		template.HTMLEscapeString(text) + "</span>")
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
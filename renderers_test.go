@@ -0,0 +1,121 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderers(t *testing.T) {
+	tests := []struct {
+		Renderers   map[string]Renderer
+		Expected    []string
+		ExpectedErr string
+	}{
+		{ // 0
+			Renderers: map[string]Renderer{"Photo": Image{Alt: "Name", Width: 64, Height: 48}},
+			Expected: []string{
+				`<td><img src="/img/widget.png" alt="Widget &#34;Pro&#34;" width="64" height="48" loading="lazy"></td>`,
+				"<td>javascript:alert(1)</td>",
+				"<td></td>",
+			},
+		},
+		{ // 1
+			Renderers: map[string]Renderer{"Photo": Image{Class: "thumb", Eager: true}},
+			Expected: []string{
+				`<td><img src="/img/widget.png" alt="" class="thumb"></td>`,
+			},
+		},
+		{ // 2
+			Renderers: map[string]Renderer{"Status": Badge{Classes: map[string]string{"in stock": "badge-ok", "backorder": "badge-warn"}, Default: "badge-other"}},
+			Expected: []string{
+				`<td><span class="badge badge-ok">in stock</span></td>`,
+				`<td><span class="badge badge-warn">backorder</span></td>`,
+				`<td><span class="badge badge-other">&lt;discontinued&gt;</span></td>`,
+			},
+		},
+		{ // 3
+			Renderers: map[string]Renderer{"Status": Badge{Classes: map[string]string{"in stock": "ok"}, Prefix: "status-", Class: "tag"}},
+			Expected: []string{
+				`<td><span class="tag ok">in stock</span></td>`,
+				`<td><span class="tag status-backorder">backorder</span></td>`,
+				`<td><span class="tag status-discontinued">&lt;discontinued&gt;</span></td>`,
+				`<td><span class="tag">—</span></td>`,
+			},
+		},
+		{ // 4
+			Renderers: map[string]Renderer{"Active": BoolIcon{}},
+			Expected: []string{
+				`<td><span class="icon icon-true" aria-hidden="true">✓</span><span class="visually-hidden">Yes</span></td>`,
+				`<td><span class="icon icon-false" aria-hidden="true">✗</span><span class="visually-hidden">No</span></td>`,
+				"<td>maybe</td>",
+			},
+		},
+		{ // 5
+			Renderers: map[string]Renderer{"Active": BoolIcon{True: "●", FalseText: "Inactive", Class: "state"}},
+			Expected: []string{
+				`<td><span class="state state-true" aria-hidden="true">●</span><span class="visually-hidden">Yes</span></td>`,
+				`<td><span class="state state-false" aria-hidden="true">✗</span><span class="visually-hidden">Inactive</span></td>`,
+			},
+		},
+		{ // 6
+			Renderers:   map[string]Renderer{"Photo": Image{Alt: "Description"}},
+			ExpectedErr: `unknown column: "Description"`,
+		},
+		{ // 7
+			Renderers:   map[string]Renderer{"Price": BoolIcon{}},
+			ExpectedErr: `unknown column: "Price"`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.Renderers = test.Renderers
+		h.CSV = [][]string{
+			[]string{"Name", "Photo", "Status", "Active"},
+			[]string{`Widget "Pro"`, "/img/widget.png", "in stock", "yes"},
+			[]string{"Gadget", "javascript:alert(1)", "backorder", "0"},
+			[]string{"Gizmo", "", "<discontinued>", "maybe"},
+			[]string{"Doohickey", "", "—", ""},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q; want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		for _, v := range test.Expected {
+			if !strings.Contains(buf.String(), v) {
+				t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), v)
+			}
+		}
+	}
+}
+
+func TestRendererLink(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.Renderers = map[string]Renderer{"Photo": Image{Alt: "Name"}}
+	h.Links = map[string]Link{"Photo": {URL: "/products/{Name}"}}
+	h.CSV = [][]string{
+		[]string{"Name", "Photo"},
+		[]string{"Widget", "/img/widget.png"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+	want := `<td><a href="/products/Widget"><img src="/img/widget.png" alt="Widget" loading="lazy"></a></td>`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got %q; want it to contain %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,221 @@
+package csv2htmltable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var errRule = errors.New("invalid rule")
+
+// Rule is a conditional formatting rule: it adds a class, an inline style,
+// or both to the body cells, or rows, that match it.  A rule matches a row
+// when the row matches its When expression, see Expr, and, if Top or Bottom
+// is set, its Column's value is one of the largest or smallest values in the
+// column.  E.g.
+//
+//	Rule{Column: "Balance", When: "Balance < 0", Class: "negative"}
+//	Rule{When: `Status != "closed" && Due < "2016-10-01"`, Row: true, Class: "overdue"}
+//	Rule{Column: "Spent", When: "Spent > Budget", Style: "color: red"}
+//	Rule{Column: "Revenue", Top: 3, Class: "top"}
+//
+// Rules apply to the body's rows, not to subtotal or footer rows.  When more
+// than one rule matches a cell or row, their classes and styles are combined.
+type Rule struct {
+	// The key of the column whose cells the rule formats and whose values Top
+	// and Bottom rank.  It's required unless the rule is a Row rule without
+	// Top or Bottom.
+	Column string
+	// A filter expression that a row must match; if empty, every row
+	// matches.
+	When string
+	// If > 0, only the rows with one of the Top largest, or Bottom smallest,
+	// numeric values in the Column match; ties all match.
+	Top, Bottom int
+	// If true, the rule formats the matching rows instead of the Column's
+	// cells.
+	Row bool
+	// The class and inline style added to the matching cells or rows.
+	Class string
+	Style string
+}
+
+// rule is a Rule resolved against the output columns.
+type rule struct {
+	Rule
+	col    int                        // the Column's index; -1 if it isn't set
+	match  func(record []string) bool // the When expression; nil if it isn't set
+	min    float64                    // the smallest value that matches Top
+	max    float64                    // the largest value that matches Bottom
+	ranked bool                       // whether min and max are set
+}
+
+// styling is the classes and styles that rules add to a cell or row.
+type styling struct {
+	classes []string
+	styles  []string
+}
+
+func (s *styling) add(r *rule) {
+	if r.Class != "" {
+		s.classes = append(s.classes, r.Class)
+	}
+	if r.Style != "" {
+		s.styles = append(s.styles, strings.TrimRight(strings.TrimSpace(r.Style), ";"))
+	}
+}
+
+// class returns the styling's classes appended to base, which may be empty.
+func (s styling) class(base string) string {
+	if base == "" {
+		return strings.Join(s.classes, " ")
+	}
+	return strings.Join(append([]string{base}, s.classes...), " ")
+}
+
+// style appends the styling's style attribute, if it has one, to a.
+func (s styling) style(a attrs) attrs {
+	if len(s.styles) == 0 {
+		return a
+	}
+	return append(a, attr{"style", strings.Join(s.styles, "; ")})
+}
+
+// setRules resolves the Rules against the output columns and ranks the
+// values of the columns of the Top and Bottom rules.
+func (h *HTMLTable) setRules() error {
+	h.rules = nil
+	for i, r := range h.Rules {
+		c := rule{Rule: r, col: -1}
+		if r.Column != "" {
+			c.col = h.columnIndex(r.Column)
+			if c.col < 0 {
+				return unknownColumnErr(r.Column)
+			}
+		} else if !r.Row || r.Top > 0 || r.Bottom > 0 {
+			return fmt.Errorf("%s: rule %d: a column is required", errRule, i)
+		}
+		if r.Top < 0 || r.Bottom < 0 {
+			return fmt.Errorf("%s: rule %d: Top and Bottom can't be negative", errRule, i)
+		}
+		if strings.TrimSpace(r.When) != "" {
+			e, err := ParseExpr(r.When)
+			if err != nil {
+				return err
+			}
+			c.match, err = e.bind(h.columnIndex)
+			if err != nil {
+				return err
+			}
+		}
+		if r.Top > 0 || r.Bottom > 0 {
+			c.rank(h.bodyRecords())
+		}
+		h.rules = append(h.rules, c)
+	}
+	return nil
+}
+
+// bodyRecords returns the body's rows as records.
+func (h *HTMLTable) bodyRecords() [][]string {
+	if len(h.Rows) == 0 {
+		return h.CSV
+	}
+	records := make([][]string, len(h.Rows))
+	for i := range h.Rows {
+		records[i] = h.rowRecord(i)
+	}
+	return records
+}
+
+// rank sets the thresholds of the Top and Bottom values of the rule's
+// column.
+func (r *rule) rank(records [][]string) {
+	var values []float64
+	for _, rec := range records {
+		if f, ok := ParseNumber(field(rec, r.col)); ok {
+			values = append(values, f)
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	sort.Float64s(values)
+	r.ranked = true
+	r.min, r.max = values[0], values[len(values)-1]
+	if r.Top > 0 && r.Top < len(values) {
+		r.min = values[len(values)-r.Top]
+	}
+	if r.Bottom > 0 && r.Bottom < len(values) {
+		r.max = values[r.Bottom-1]
+	}
+}
+
+// matches returns whether the record matches the rule.
+func (r *rule) matches(rec []string) bool {
+	if r.match != nil && !r.match(rec) {
+		return false
+	}
+	if r.Top == 0 && r.Bottom == 0 {
+		return true
+	}
+	if !r.ranked {
+		return false
+	}
+	f, ok := ParseNumber(field(rec, r.col))
+	if !ok {
+		return false
+	}
+	if r.Top > 0 && f >= r.min {
+		return true
+	}
+	return r.Bottom > 0 && f <= r.max
+}
+
+// applyRules returns the styling of a record's row and of its cells, by
+// column.
+func (h *HTMLTable) applyRules(rec []string) (row styling, cells map[int]styling) {
+	for i := range h.rules {
+		r := &h.rules[i]
+		if !r.matches(rec) {
+			continue
+		}
+		if r.Row {
+			row.add(r)
+			continue
+		}
+		if cells == nil {
+			cells = make(map[int]styling)
+		}
+		c := cells[r.col]
+		c.add(r)
+		cells[r.col] = c
+	}
+	return row, cells
+}
+
+// ReadRules reads rules from JSON: an array of objects whose keys are the
+// names of Rule's fields, e.g.
+//
+//	[{"Column": "Balance", "When": "Balance < 0", "Class": "negative"}]
+//
+// Keys are matched case-insensitively.
+func ReadRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&rules)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", errRule, err)
+	}
+	return rules, nil
+}
+
+// IsRuleErr returns whether or not the error was a result of an invalid
+// conditional formatting rule.
+func IsRuleErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errRule.Error())
+}
@@ -0,0 +1,160 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		Rules       []Rule
+		Expected    []string
+		ExpectedErr string
+	}{
+		{ // 0
+			Rules: []Rule{{Column: "Balance", When: "Balance < 0", Class: "negative"}},
+			Expected: []string{
+				`<td class="negative">-20</td>`,
+				"<td>150</td>",
+			},
+		},
+		{ // 1
+			Rules: []Rule{{When: `Due < "2016-10-01" && Status != "closed"`, Row: true, Class: "overdue", Style: "font-weight: bold;"}},
+			Expected: []string{
+				`<tr class="overdue" style="font-weight: bold">
+            <td>Alice</td>`,
+				`<tr>
+            <td>Bob</td>`,
+				`<tr>
+            <td>Carol</td>`,
+			},
+		},
+		{ // 2
+			Rules: []Rule{
+				{Column: "Spent", When: "Spent > Budget", Style: "color: red"},
+				{Column: "Spent", When: "Name =~ \"^A\"", Class: "a", Style: "font-style: italic"},
+			},
+			Expected: []string{
+				`<td class="a" style="color: red; font-style: italic">120</td>`,
+				"<td>80</td>",
+				`<td style="color: red">300</td>`,
+			},
+		},
+		{ // 3
+			Rules: []Rule{{Column: "Balance", Top: 1, Class: "top"}, {Column: "Balance", Bottom: 2, Class: "bottom"}},
+			Expected: []string{
+				`<td class="bottom">-20</td>`,
+				`<td class="top">150</td>`,
+				`<td class="bottom">90</td>`,
+			},
+		},
+		{ // 4
+			Rules: []Rule{{Column: "Balance", Top: 2, When: `Status == "open"`, Class: "top"}},
+			Expected: []string{
+				"<td>-20</td>",
+				`<td class="top">150</td>`,
+				"<td>90</td>",
+			},
+		},
+		{ // 5
+			Rules:       []Rule{{Column: "Owner", Class: "x"}},
+			ExpectedErr: `unknown column: "Owner"`,
+		},
+		{ // 6
+			Rules:       []Rule{{Top: 2, Row: true, Class: "x"}},
+			ExpectedErr: "invalid rule: rule 0: a column is required",
+		},
+		{ // 7
+			Rules:       []Rule{{Column: "Balance", When: "Balance <", Class: "x"}},
+			ExpectedErr: "expression error at position 10: expected column, string, number, or null, found end of expression",
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.Rules = test.Rules
+		h.CSV = [][]string{
+			[]string{"Name", "Status", "Due", "Balance", "Budget", "Spent"},
+			[]string{"Alice", "open", "2016-09-01", "-20", "100", "120"},
+			[]string{"Bob", "open", "2016-11-01", "150", "100", "80"},
+			[]string{"Carol", "closed", "2016-09-15", "90", "200", "300"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q; want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		for _, v := range test.Expected {
+			if !strings.Contains(buf.String(), v) {
+				t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), v)
+			}
+		}
+	}
+}
+
+func TestRulesRows(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.HeaderRows = [][]string{{"Item", "Qty"}}
+	h.Rows = []Row{
+		{Cells: []Cell{{Text: "Widget", Header: true, Class: "item"}, {Text: "0"}}, Class: "stock"},
+		{Cells: []Cell{{Text: "Gadget", Header: true}, {Text: "5"}}},
+	}
+	h.Rules = []Rule{
+		{Column: "Item", When: "Qty == 0", Class: "empty"},
+		{When: "Qty == 0", Row: true, Class: "out"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+	for _, want := range []string{
+		`<tr class="stock out">
+            <th scope="row" class="item empty">Widget</th>`,
+		`<tr>
+            <th scope="row">Gadget</th>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("got %q; want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestReadRules(t *testing.T) {
+	rules, err := ReadRules(strings.NewReader(`[
+		{"column": "Balance", "when": "Balance < 0", "class": "negative"},
+		{"When": "Overdue == \"yes\"", "Row": true, "Style": "background: #fee"},
+		{"Column": "Revenue", "Top": 3, "Class": "top"}
+	]`))
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+	want := []Rule{
+		{Column: "Balance", When: "Balance < 0", Class: "negative"},
+		{When: `Overdue == "yes"`, Row: true, Style: "background: #fee"},
+		{Column: "Revenue", Top: 3, Class: "top"},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("got %d rules; want %d", len(rules), len(want))
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("%d: got %+v; want %+v", i, rules[i], want[i])
+		}
+	}
+	_, err = ReadRules(strings.NewReader(`[{"Colour": "red"}]`))
+	if err == nil || !IsRuleErr(err) {
+		t.Errorf("got %v; want a rule error", err)
+	}
+}
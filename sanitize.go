@@ -0,0 +1,182 @@
+package csv2htmltable
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// Sanitizer sanitizes HTML using an allowlist of elements and attributes.
+// Elements that aren't allowed are removed but their content is kept, except
+// for elements like script and style whose content is removed as well.
+// Attributes that aren't allowed are removed, as are URL attributes, e.g.
+// href, whose URL has a scheme that isn't allowed.  Text is escaped and
+// unclosed elements are closed.
+type Sanitizer struct {
+	// The allowed elements and, for each, its allowed attributes.  Event
+	// handler attributes, e.g. onclick, are never allowed.
+	Elements map[string][]string
+	// The URL schemes that URL attributes may use; if nil, the
+	// DefaultURLSchemes are used.  Relative URLs are always allowed.
+	URLSchemes []string
+}
+
+// DefaultSanitizer allows text level formatting elements, lists, paragraphs,
+// and links.
+var DefaultSanitizer = &Sanitizer{
+	Elements: map[string][]string{
+		"a":      {"href", "title"},
+		"abbr":   {"title"},
+		"b":      nil,
+		"br":     nil,
+		"code":   nil,
+		"del":    nil,
+		"em":     nil,
+		"i":      nil,
+		"ins":    nil,
+		"li":     nil,
+		"mark":   nil,
+		"ol":     nil,
+		"p":      nil,
+		"s":      nil,
+		"small":  nil,
+		"span":   {"title"},
+		"strong": nil,
+		"sub":    nil,
+		"sup":    nil,
+		"u":      nil,
+		"ul":     nil,
+	},
+}
+
+var (
+	htmlTag  = regexp.MustCompile(`<!--[\s\S]*?(?:-->|$)|<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:\s+[^\s/>"'=]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'>]+))?)*)\s*/?>`)
+	htmlAttr = regexp.MustCompile(`([^\s/>"'=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+)
+
+// The elements whose content is removed along with them.
+var rawElements = map[string]bool{
+	"iframe": true, "noembed": true, "noframes": true, "noscript": true,
+	"object": true, "script": true, "style": true, "template": true,
+	"textarea": true, "title": true, "xmp": true,
+}
+
+// The elements that don't have an end tag.
+var voidElements = map[string]bool{
+	"area": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "source": true, "track": true, "wbr": true,
+}
+
+// The attributes whose values are URLs.
+var urlAttrs = map[string]bool{
+	"action": true, "background": true, "cite": true, "formaction": true,
+	"href": true, "longdesc": true, "poster": true, "src": true,
+	"srcset": true, "xlink:href": true,
+}
+
+// Sanitize returns the received HTML with everything that isn't allowed
+// removed.
+func (z *Sanitizer) Sanitize(s string) template.HTML {
+	var b strings.Builder
+	var open []string
+	for len(s) > 0 {
+		loc := htmlTag.FindStringSubmatchIndex(s)
+		if loc == nil {
+			b.WriteString(escapeText(s))
+			break
+		}
+		b.WriteString(escapeText(s[:loc[0]]))
+		tag := s[loc[0]:loc[1]]
+		s = s[loc[1]:]
+		if strings.HasPrefix(tag, "<!--") {
+			continue
+		}
+		end := loc[3] > loc[2]
+		name := strings.ToLower(tag[loc[4]-loc[0] : loc[5]-loc[0]])
+		if rawElements[name] {
+			if !end {
+				s = skipElement(s, name)
+			}
+			continue
+		}
+		allowed, ok := z.Elements[name]
+		if !ok {
+			continue
+		}
+		if end {
+			// Close the element and any elements left open inside it; if it
+			// isn't open, the end tag is dropped.
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != name {
+					continue
+				}
+				for len(open) > i {
+					b.WriteString("</" + open[len(open)-1] + ">")
+					open = open[:len(open)-1]
+				}
+				break
+			}
+			continue
+		}
+		b.WriteString("<" + name)
+		b.WriteString(z.attrs(tag[loc[6]-loc[0]:loc[7]-loc[0]], allowed))
+		b.WriteString(">")
+		if !voidElements[name] {
+			open = append(open, name)
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return template.HTML(b.String())
+}
+
+// attrs returns the allowed attributes of a start tag, each with a leading
+// space and its value escaped.
+func (z *Sanitizer) attrs(s string, allowed []string) string {
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for _, m := range htmlAttr.FindAllStringSubmatch(s, -1) {
+		k := strings.ToLower(m[1])
+		if seen[k] || strings.HasPrefix(k, "on") || !contains(allowed, k) {
+			continue
+		}
+		seen[k] = true
+		v := html.UnescapeString(m[2] + m[3] + m[4])
+		if urlAttrs[k] && !allowedURL(v, z.URLSchemes) {
+			continue
+		}
+		b.WriteString(" " + k + `="` + template.HTMLEscapeString(v) + `"`)
+	}
+	return b.String()
+}
+
+// skipElement returns what follows the end tag of the named element; if it
+// doesn't have one, the rest of the HTML is skipped.
+func skipElement(s, name string) string {
+	i := strings.Index(strings.ToLower(s), "</"+name)
+	if i < 0 {
+		return ""
+	}
+	s = s[i:]
+	if j := strings.IndexByte(s, '>'); j >= 0 {
+		return s[j+1:]
+	}
+	return ""
+}
+
+// escapeText escapes text, keeping any character references that it
+// already has.
+func escapeText(s string) string {
+	return template.HTMLEscapeString(html.UnescapeString(s))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
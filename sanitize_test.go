@@ -0,0 +1,61 @@
+package csv2htmltable
+
+import (
+	"html/template"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		Value    string
+		Expected template.HTML
+	}{
+		{"plain & simple", "plain &amp; simple"},                                                                                 // 0
+		{"Tom &amp; Jerry", "Tom &amp; Jerry"},                                                                                   // 1
+		{"<b>bold</b> and <EM>em</EM>", "<b>bold</b> and <em>em</em>"},                                                           // 2
+		{"<script>alert(1)</script>ok", "ok"},                                                                                    // 3
+		{"<STYLE>p{}</style><p>x</p>", "<p>x</p>"},                                                                               // 4
+		{`<a href="https://example.com" onclick="x()">x</a>`, `<a href="https://example.com">x</a>`},                             // 5
+		{`<a href="javascript:alert(1)">x</a>`, "<a>x</a>"},                                                                      // 6
+		{`<a href=" jav&#x09;ascript:alert(1)">x</a>`, "<a>x</a>"},                                                               // 7
+		{`<a href='/docs?a=1&amp;b=2' title="a &quot;b&quot;">x</a>`, `<a href="/docs?a=1&amp;b=2" title="a &#34;b&#34;">x</a>`}, // 8
+		{`<img src=x onerror=alert(1)>text`, "text"},                                                                             // 9
+		{"<div><b>open", "<b>open</b>"},                                                                                          // 10
+		{"<b><i>x</b></i>", "<b><i>x</i></b>"},                                                                                   // 11
+		{"</p>stray", "stray"},                                                                                                   // 12
+		{"a < b > c", "a &lt; b &gt; c"},                                                                                         // 13
+		{"<!-- <script>x</script> -->after", "after"},                                                                            // 14
+		{"line<br/>break", "line<br>break"},                                                                                      // 15
+		{"<script>never closed", ""},                                                                                             // 16
+		{`<span title="t" class="c">x</span>`, `<span title="t">x</span>`},                                                       // 17
+	}
+	for i, test := range tests {
+		got := DefaultSanitizer.Sanitize(test.Value)
+		if got != test.Expected {
+			t.Errorf("%d: got %q; want %q", i, got, test.Expected)
+		}
+	}
+}
+
+func TestSanitizerConfig(t *testing.T) {
+	z := &Sanitizer{
+		Elements:   map[string][]string{"a": {"href", "onclick"}, "img": {"src", "alt"}},
+		URLSchemes: []string{"https"},
+	}
+	tests := []struct {
+		Value    string
+		Expected template.HTML
+	}{
+		{`<a href="http://example.com">x</a>`, "<a>x</a>"},                                                 // 0
+		{`<a href="https://example.com" onclick="x()">x</a>`, `<a href="https://example.com">x</a>`},       // 1
+		{`<img src="https://example.com/a.png" alt="A">`, `<img src="https://example.com/a.png" alt="A">`}, // 2
+		{`<img src="data:image/png;base64,AAAA" alt="A">`, `<img alt="A">`},                                // 3
+		{"<b>x</b>", "x"}, // 4
+	}
+	for i, test := range tests {
+		got := z.Sanitize(test.Value)
+		if got != test.Expected {
+			t.Errorf("%d: got %q; want %q", i, got, test.Expected)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var errColorScale = errors.New("invalid color scale")
+
+// ColorScale shades a numeric column's cells, as a heatmap, with background
+// colors interpolated between the scale's colors according to each cell's
+// value.  Each cell's text color is black or white, whichever has the
+// greater contrast with its background.  Cells that aren't numbers aren't
+// shaded.
+type ColorScale struct {
+	// The scale's colors, in #rgb or #rrggbb form: either the colors of the
+	// column's smallest and largest values, or of its smallest, middle, and
+	// largest values.  If nil, the DefaultScaleColors are used.
+	Colors []string
+	// The percentiles, from 0 to 100, of the column's values that the Colors
+	// are at, e.g. 10 and 90 so that outliers don't flatten the scale; values
+	// beyond the ends get the end colors.  If nil, the Colors are at the
+	// minimum, the median if there are three colors, and the maximum.
+	Percentiles []float64
+}
+
+// DataBar draws a bar in each of a numeric column's cells whose length is
+// proportional to the cell's value.  The bars start at the column's
+// smallest value or zero, whichever is less.
+type DataBar struct {
+	// The bar's color; if empty, #638ec6 is used.
+	Color string
+	// If true, the bar is an inline SVG that precedes the cell's content,
+	// instead of a CSS gradient in the cell's background.
+	SVG bool
+}
+
+const defaultBarColor = "#638ec6"
+
+// DefaultScaleColors are the colors of a ColorScale that doesn't specify
+// any: red, through yellow, to green.
+var DefaultScaleColors = []string{"#f8696b", "#ffeb84", "#63be7b"}
+
+// scale is a ColorScale resolved against a column's values.
+type scale struct {
+	stops  []float64
+	colors [][3]uint8
+}
+
+// bar is a DataBar resolved against a column's values.
+type bar struct {
+	DataBar
+	lo, hi float64
+}
+
+// setScales resolves the ColorScales and DataBars against the output columns
+// and their values.
+func (h *HTMLTable) setScales() error {
+	h.scales, h.bars = nil, nil
+	if len(h.ColorScales) == 0 && len(h.DataBars) == 0 {
+		return nil
+	}
+	records := h.bodyRecords()
+	for k, cs := range h.ColorScales {
+		i := h.columnIndex(k)
+		if i < 0 {
+			return unknownColumnErr(k)
+		}
+		s, err := newScale(cs, columnValues(records, i))
+		if err != nil {
+			return fmt.Errorf("%s: %s: %s", errColorScale, k, err)
+		}
+		if h.scales == nil {
+			h.scales = make(map[int]*scale)
+		}
+		h.scales[i] = s
+	}
+	for k, db := range h.DataBars {
+		i := h.columnIndex(k)
+		if i < 0 {
+			return unknownColumnErr(k)
+		}
+		if db.Color == "" {
+			db.Color = defaultBarColor
+		}
+		if _, err := parseColor(db.Color); err != nil {
+			return fmt.Errorf("%s: %s: %s", errColorScale, k, err)
+		}
+		b := &bar{DataBar: db}
+		for _, v := range columnValues(records, i) {
+			b.lo, b.hi = math.Min(b.lo, v), math.Max(b.hi, v)
+		}
+		if h.bars == nil {
+			h.bars = make(map[int]*bar)
+		}
+		h.bars[i] = b
+	}
+	return nil
+}
+
+// newScale returns the scale of a ColorScale for the received values.
+func newScale(cs ColorScale, values []float64) (*scale, error) {
+	if cs.Colors == nil {
+		cs.Colors = DefaultScaleColors
+	}
+	if len(cs.Colors) != 2 && len(cs.Colors) != 3 {
+		return nil, fmt.Errorf("expected 2 or 3 colors, got %d", len(cs.Colors))
+	}
+	pcts := cs.Percentiles
+	if pcts == nil {
+		pcts = []float64{0, 100}
+		if len(cs.Colors) == 3 {
+			pcts = []float64{0, 50, 100}
+		}
+	}
+	if len(pcts) != len(cs.Colors) {
+		return nil, fmt.Errorf("expected %d percentiles, got %d", len(cs.Colors), len(pcts))
+	}
+	s := &scale{}
+	sort.Float64s(values)
+	for i, c := range cs.Colors {
+		rgb, err := parseColor(c)
+		if err != nil {
+			return nil, err
+		}
+		if pcts[i] < 0 || pcts[i] > 100 || i > 0 && pcts[i] < pcts[i-1] {
+			return nil, fmt.Errorf("percentiles must be ascending, from 0 to 100")
+		}
+		s.colors = append(s.colors, rgb)
+		s.stops = append(s.stops, percentile(values, pcts[i]))
+	}
+	return s, nil
+}
+
+// color returns the color of the received value.
+func (s *scale) color(v float64) [3]uint8 {
+	if v <= s.stops[0] {
+		return s.colors[0]
+	}
+	for k := 1; k < len(s.stops); k++ {
+		if v < s.stops[k] {
+			t := (v - s.stops[k-1]) / (s.stops[k] - s.stops[k-1])
+			return mixColors(s.colors[k-1], s.colors[k], t)
+		}
+	}
+	return s.colors[len(s.colors)-1]
+}
+
+// styleCell returns the styling of the j'th cell of the received row with the
+// styles of its column's ColorScale and DataBar, if it has them, preceding
+// the received styling's styles, so that the styles of rules take
+// precedence.
+func (h *HTMLTable) styleCell(rec []string, j int, st styling) styling {
+	s, b := h.scales[j], h.bars[j]
+	if s == nil && (b == nil || b.SVG) {
+		return st
+	}
+	v, ok := ParseNumber(field(rec, j))
+	if !ok {
+		return st
+	}
+	var styles []string
+	if s != nil {
+		bg := s.color(v)
+		styles = append(styles, "background-color: "+formatColor(bg), "color: "+textColor(bg))
+	}
+	if b != nil && !b.SVG {
+		pct := formatFloat(math.Round(b.fraction(v)*1000) / 10)
+		styles = append(styles, "background-image: linear-gradient(to right, "+b.Color+" "+pct+"%, transparent "+pct+"%)")
+	}
+	st.styles = append(styles, st.styles...)
+	return st
+}
+
+// barSVG returns the inline SVG data bar of the j'th cell of the received
+// row; if the column doesn't have an SVG DataBar or the value isn't a number,
+// "" is returned.  The bar is hidden from assistive technology, which reads
+// the cell's value instead.
+func (h *HTMLTable) barSVG(rec []string, j int) template.HTML {
+	b := h.bars[j]
+	if b == nil || !b.SVG {
+		return ""
+	}
+	v, ok := ParseNumber(field(rec, j))
+	if !ok {
+		return ""
+	}
+	w := formatFloat(math.Round(b.fraction(v)*1000) / 10)
+	return template.HTML(`<svg class="data-bar" width="60" height="10" viewBox="0 0 100 10" aria-hidden="true" focusable="false">` +
+		`<rect width="` + w + `" height="10" fill="` + template.HTMLEscapeString(b.Color) + `"></rect></svg> `)
+}
+
+// fraction returns the fraction of the bar's range that the value fills.
+func (b *bar) fraction(v float64) float64 {
+	if b.hi == b.lo {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (v-b.lo)/(b.hi-b.lo)))
+}
+
+// columnValues returns the numeric values of the i'th column of the records.
+func columnValues(records [][]string, i int) []float64 {
+	var values []float64
+	for _, rec := range records {
+		if f, ok := ParseNumber(field(rec, i)); ok {
+			values = append(values, f)
+		}
+	}
+	return values
+}
+
+// percentile returns the p'th percentile of the sorted values, interpolating
+// between the values that it falls between; if there aren't any values, 0 is
+// returned.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	x := p / 100 * float64(len(sorted)-1)
+	i := int(x)
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (x-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// parseColor parses a #rgb or #rrggbb color.
+func parseColor(color string) ([3]uint8, error) {
+	var rgb [3]uint8
+	s := strings.TrimPrefix(color, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 || !strings.HasPrefix(color, "#") {
+		return rgb, fmt.Errorf("invalid color %q: expected #rgb or #rrggbb", color)
+	}
+	for i := range rgb {
+		v, err := strconv.ParseUint(s[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return rgb, fmt.Errorf("invalid color %q: expected #rgb or #rrggbb", color)
+		}
+		rgb[i] = uint8(v)
+	}
+	return rgb, nil
+}
+
+// formatColor returns the color in #rrggbb form.
+func formatColor(c [3]uint8) string {
+	return fmt.Sprintf("#%02x%02x%02x", c[0], c[1], c[2])
+}
+
+// mixColors returns the color that is t of the way from a to b.
+func mixColors(a, b [3]uint8, t float64) [3]uint8 {
+	var c [3]uint8
+	for i := range c {
+		c[i] = uint8(math.Round(float64(a[i]) + t*(float64(b[i])-float64(a[i]))))
+	}
+	return c
+}
+
+// textColor returns black or white, whichever has the greater contrast with
+// the background color.
+func textColor(bg [3]uint8) string {
+	c := formatColor(bg)
+	black, _ := ContrastRatio("#000000", c)
+	white, _ := ContrastRatio("#ffffff", c)
+	if white > black {
+		return "#ffffff"
+	}
+	return "#000000"
+}
+
+// ParseColorScales parses a comma separated list of column keys, each
+// optionally followed by = and the scale's colors, separated by colons, e.g.
+// "Score,Load=#ffffff:#ff0000".  Columns without colors use the
+// DefaultScaleColors.
+func ParseColorScales(s string) (map[string]ColorScale, error) {
+	cols, err := ParseColumns(s)
+	if err != nil {
+		return nil, err
+	}
+	scales := make(map[string]ColorScale, len(cols))
+	for _, k := range cols {
+		cs := ColorScale{Colors: DefaultScaleColors}
+		if i := strings.Index(k, "="); i >= 0 {
+			cs.Colors = strings.Split(k[i+1:], ":")
+			for j := range cs.Colors {
+				cs.Colors[j] = strings.TrimSpace(cs.Colors[j])
+			}
+			k = strings.TrimSpace(k[:i])
+		}
+		scales[k] = cs
+	}
+	return scales, nil
+}
+
+// IsColorScaleErr returns whether or not the error was a result of an
+// invalid ColorScale or DataBar.
+func IsColorScaleErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errColorScale.Error())
+}
@@ -0,0 +1,154 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestColorScales(t *testing.T) {
+	tests := []struct {
+		ColorScales map[string]ColorScale
+		DataBars    map[string]DataBar
+		Rules       []Rule
+		Expected    []string
+		ExpectedErr string
+	}{
+		{ // 0
+			ColorScales: map[string]ColorScale{"Score": {Colors: []string{"#fff", "#000"}}},
+			Expected: []string{
+				`<td style="background-color: #ffffff; color: #000000">0</td>`,
+				`<td style="background-color: #bfbfbf; color: #000000">25</td>`,
+				`<td style="background-color: #000000; color: #ffffff">100</td>`,
+				"<td>n/a</td>",
+			},
+		},
+		{ // 1
+			ColorScales: map[string]ColorScale{"Score": {Colors: []string{"#f00", "#fff", "#00f"}}},
+			Expected: []string{
+				`<td style="background-color: #ff0000; color: #000000">0</td>`,
+				`<td style="background-color: #ffaaaa; color: #000000">25</td>`,
+				`<td style="background-color: #0000ff; color: #ffffff">100</td>`,
+				`<td style="background-color: #ccccff; color: #000000">50</td>`,
+			},
+		},
+		{ // 2
+			ColorScales: map[string]ColorScale{"Score": {Colors: []string{"#fff", "#000"}, Percentiles: []float64{0, 50}}},
+			Expected: []string{
+				`<td style="background-color: #000000; color: #ffffff">50</td>`,
+				`<td style="background-color: #000000; color: #ffffff">100</td>`,
+				`<td style="background-color: #ffffff; color: #000000">0</td>`,
+			},
+		},
+		{ // 3
+			DataBars: map[string]DataBar{"Score": {}},
+			Expected: []string{
+				`<td style="background-image: linear-gradient(to right, #638ec6 25%, transparent 25%)">25</td>`,
+				`<td style="background-image: linear-gradient(to right, #638ec6 0%, transparent 0%)">0</td>`,
+			},
+		},
+		{ // 4
+			DataBars: map[string]DataBar{"Score": {Color: "#0a0", SVG: true}},
+			Expected: []string{
+				`<td><svg class="data-bar" width="60" height="10" viewBox="0 0 100 10" aria-hidden="true" focusable="false"><rect width="50" height="10" fill="#0a0"></rect></svg> 50</td>`,
+				"<td>n/a</td>",
+			},
+		},
+		{ // 5
+			ColorScales: map[string]ColorScale{"Score": {Colors: []string{"#fff", "#000"}}},
+			Rules:       []Rule{{Column: "Score", When: "Score > 90", Class: "high", Style: "color: red"}},
+			Expected: []string{
+				`<td class="high" style="background-color: #000000; color: #ffffff; color: red">100</td>`,
+			},
+		},
+		{ // 6
+			ColorScales: map[string]ColorScale{"Score": {Colors: []string{"#fff"}}},
+			ExpectedErr: "invalid color scale: Score: expected 2 or 3 colors, got 1",
+		},
+		{ // 7
+			ColorScales: map[string]ColorScale{"Score": {Colors: []string{"#fff", "red"}}},
+			ExpectedErr: `invalid color scale: Score: invalid color "red": expected #rgb or #rrggbb`,
+		},
+		{ // 8
+			ColorScales: map[string]ColorScale{"Score": {Colors: []string{"#fff", "#000"}, Percentiles: []float64{90, 10}}},
+			ExpectedErr: "invalid color scale: Score: percentiles must be ascending, from 0 to 100",
+		},
+		{ // 9
+			DataBars:    map[string]DataBar{"Rank": {}},
+			ExpectedErr: `unknown column: "Rank"`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.ColorScales = test.ColorScales
+		h.DataBars = test.DataBars
+		h.Rules = test.Rules
+		h.CSV = [][]string{
+			[]string{"Name", "Score"},
+			[]string{"a", "0"},
+			[]string{"b", "25"},
+			[]string{"c", "100"},
+			[]string{"d", "n/a"},
+			[]string{"e", "50"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q; want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		for _, v := range test.Expected {
+			if !strings.Contains(buf.String(), v) {
+				t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), v)
+			}
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+	tests := []struct {
+		p        float64
+		expected float64
+	}{
+		{p: 0, expected: 1},
+		{p: 50, expected: 3},
+		{p: 100, expected: 5},
+		{p: 10, expected: 1.4},
+	}
+	for i, test := range tests {
+		got := percentile(values, test.p)
+		if got < test.expected-1e-9 || got > test.expected+1e-9 {
+			t.Errorf("%d: got %v; want %v", i, got, test.expected)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("nil: got %v; want 0", got)
+	}
+}
+
+func TestParseColorScales(t *testing.T) {
+	scales, err := ParseColorScales("Score, Load=#fff : #f00")
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+	if len(scales) != 2 {
+		t.Fatalf("got %v; want 2 scales", scales)
+	}
+	if got := strings.Join(scales["Score"].Colors, ","); got != strings.Join(DefaultScaleColors, ",") {
+		t.Errorf("Score: got %q; want the default colors", got)
+	}
+	if got := strings.Join(scales["Load"].Colors, ","); got != "#fff,#f00" {
+		t.Errorf("Load: got %q; want %q", got, "#fff,#f00")
+	}
+}
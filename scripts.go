@@ -0,0 +1,144 @@
+package csv2htmltable
+
+// sortTpl is the inline script for client-side sorting.  It is placed
+// immediately after the table that it sorts so that it doesn't need the
+// table's ID.  Each sortable header has a data-col attribute with the index of
+// its column, since spanned header rows don't line up with the columns, and a
+// data-type attribute with the column's type, which determines how its values
+// are compared; empty values are always sorted last.  The rows of each tbody
+// are sorted separately and a group's header and subtotal rows stay at its
+// start and end.
+var sortTpl = `
+{{- define "sort" -}}
+<script{{if .ScriptNonce}} nonce="{{.ScriptNonce}}"{{end}}>
+(function () {
+    var table = document.currentScript.previousElementSibling;
+    if (!table.tHead || !table.tBodies.length) {
+        return;
+    }
+    var heads = table.tHead.querySelectorAll("th[data-col]");
+    var collator = window.Intl ? new Intl.Collator(undefined, {numeric: true, sensitivity: "base"}) : null;
+    function key(cell, type) {
+        var s = cell ? cell.textContent.trim() : "";
+        if (s === "") {
+            return null;
+        }
+        var v = s;
+        if (type === "numeric") {
+            v = parseFloat(s.replace(/[^0-9eE.+-]/g, ""));
+        } else if (type === "date") {
+            v = Date.parse(s);
+        }
+        return (type !== "text" && isNaN(v)) ? null : v;
+    }
+    function compare(a, b, type) {
+        if (type !== "text") {
+            return a - b;
+        }
+        if (collator) {
+            return collator.compare(a, b);
+        }
+        return a < b ? -1 : a > b ? 1 : 0;
+    }
+    function sort(th) {
+        var col = +th.getAttribute("data-col");
+        var type = th.getAttribute("data-type") || "text";
+        var dir = th.getAttribute("aria-sort") === "ascending" ? "descending" : "ascending";
+        for (var i = 0; i < heads.length; i++) {
+            if (heads[i].hasAttribute("aria-sort")) {
+                heads[i].setAttribute("aria-sort", "none");
+            }
+        }
+        th.setAttribute("aria-sort", dir);
+        for (var b = 0; b < table.tBodies.length; b++) {
+            var body = table.tBodies[b];
+            var rows = [], subtotals = [];
+            for (i = 0; i < body.rows.length; i++) {
+                var row = body.rows[i];
+                if (row.classList.contains("subtotal")) {
+                    subtotals.push(row);
+                } else if (!row.classList.contains("group")) {
+                    rows.push({row: row, index: i, key: key(row.cells[col], type)});
+                }
+            }
+            rows.sort(function (a, b) {
+                if (a.key === null || b.key === null) {
+                    return (a.key === null) - (b.key === null) || a.index - b.index;
+                }
+                var c = compare(a.key, b.key, type);
+                return (dir === "descending" ? -c : c) || a.index - b.index;
+            });
+            for (i = 0; i < rows.length; i++) {
+                body.appendChild(rows[i].row);
+            }
+            for (i = 0; i < subtotals.length; i++) {
+                body.appendChild(subtotals[i]);
+            }
+        }
+    }
+    Array.prototype.forEach.call(heads, function (th) {
+        th.addEventListener("click", function () {
+            sort(th);
+        });
+        th.addEventListener("keydown", function (e) {
+            if (e.key === "Enter" || e.key === " ") {
+                e.preventDefault();
+                sort(th);
+            }
+        });
+    });
+})();
+</script>
+{{- end}}`
+
+// filterTpl is the inline script for client-side filtering.  The search
+// input, the column filter inputs, and the status region are found using the
+// table's ID.  Group header and subtotal rows are never hidden.
+var filterTpl = `
+{{- define "filter" -}}
+<script{{if .ScriptNonce}} nonce="{{.ScriptNonce}}"{{end}}>
+(function () {
+    var table = document.getElementById({{.ID}});
+    var search = document.getElementById({{.ID}} + "-search");
+    var status = document.getElementById({{.ID}} + "-status");
+    var inputs = table.tHead ? table.tHead.querySelectorAll("input[data-col]") : [];
+    function filter() {
+        var q = search ? search.value.trim().toLowerCase() : "";
+        var cols = [];
+        for (var i = 0; i < inputs.length; i++) {
+            var v = inputs[i].value.trim().toLowerCase();
+            if (v !== "") {
+                cols.push({col: +inputs[i].getAttribute("data-col"), value: v});
+            }
+        }
+        var shown = 0, total = 0;
+        for (var b = 0; b < table.tBodies.length; b++) {
+            var rows = table.tBodies[b].rows;
+            for (i = 0; i < rows.length; i++) {
+                var row = rows[i];
+                if (row.classList.contains("group") || row.classList.contains("subtotal")) {
+                    continue;
+                }
+                var match = q === "" || row.textContent.toLowerCase().indexOf(q) !== -1;
+                for (var c = 0; match && c < cols.length; c++) {
+                    var cell = row.cells[cols[c].col];
+                    match = !!cell && cell.textContent.toLowerCase().indexOf(cols[c].value) !== -1;
+                }
+                row.hidden = !match;
+                total++;
+                if (match) {
+                    shown++;
+                }
+            }
+        }
+        status.textContent = shown + " of " + total + " rows";
+    }
+    if (search) {
+        search.addEventListener("input", filter);
+    }
+    for (var i = 0; i < inputs.length; i++) {
+        inputs[i].addEventListener("input", filter);
+    }
+})();
+</script>
+{{- end}}`
@@ -0,0 +1,223 @@
+package csv2htmltable
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SortKey is a column that the table's records are sorted by.
+type SortKey struct {
+	Column string // the column's header or 0-based index
+	Desc   bool   // if true, the column is sorted in descending order
+}
+
+// Collator compares strings according to the rules of a locale.  It is
+// satisfied by golang.org/x/text/collate's *Collator.
+type Collator interface {
+	CompareString(a, b string) int
+}
+
+// ParseSort parses a comma separated list of sort keys, e.g.
+// "Region,-Revenue".  A key that is prefixed with a "-" is sorted in
+// descending order; a "+" prefix, or no prefix, sorts in ascending order.
+func ParseSort(s string) ([]SortKey, error) {
+	cols, err := ParseColumns(s)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]SortKey, 0, len(cols))
+	for _, c := range cols {
+		var k SortKey
+		switch {
+		case strings.HasPrefix(c, "-"):
+			k.Desc = true
+			c = c[1:]
+		case strings.HasPrefix(c, "+"):
+			c = c[1:]
+		}
+		k.Column = strings.TrimSpace(c)
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// sortKey is the value of a record's field, parsed according to its
+// column's type, so that it's only parsed once.
+type sortKey struct {
+	null bool
+	num  float64
+	t    time.Time
+	s    string
+}
+
+// sortRecords sorts the records by the SortBy columns.  The sort is stable,
+// so records whose sort columns are equal keep their order, and empty values
+// are always sorted last.  The columns are resolved against the columns as
+// they are in the CSV.
+func (h *HTMLTable) sortRecords() error {
+	h.sortCol, h.sortDesc = -1, false
+	if len(h.SortBy) == 0 {
+		return nil
+	}
+	idx := make([]int, len(h.SortBy))
+	types := make([]ColumnType, len(h.SortBy))
+	for i, k := range h.SortBy {
+		idx[i] = h.columnIndex(k.Column)
+		if idx[i] < 0 {
+			return unknownColumnErr(k.Column)
+		}
+		types[i] = h.sourceType(idx[i])
+	}
+	h.sortCol, h.sortDesc = idx[0], h.SortBy[0].Desc
+
+	keys := make([][]sortKey, len(h.CSV))
+	for i, rec := range h.CSV {
+		keys[i] = make([]sortKey, len(idx))
+		for j, col := range idx {
+			var v string
+			if col < len(rec) {
+				v = rec[col]
+			}
+			keys[i][j] = makeSortKey(v, types[j])
+		}
+	}
+	// Sort a permutation so that the keys and records stay together.
+	perm := make([]int, len(h.CSV))
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(a, b int) bool {
+		ka, kb := keys[perm[a]], keys[perm[b]]
+		for j := range idx {
+			if ka[j].null || kb[j].null {
+				if ka[j].null == kb[j].null {
+					continue
+				}
+				return kb[j].null
+			}
+			c := compareSortKeys(ka[j], kb[j], types[j], h.Collator)
+			if c == 0 {
+				continue
+			}
+			if h.SortBy[j].Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	records := make([][]string, len(h.CSV))
+	for i, p := range perm {
+		records[i] = h.CSV[p]
+	}
+	h.CSV = records
+	return nil
+}
+
+// sourceType returns the type of the i'th column in the CSV.
+func (h *HTMLTable) sourceType(i int) ColumnType {
+	for k, t := range h.ColumnTypes {
+		if h.columnIndex(k) == i {
+			return t
+		}
+	}
+	return InferType(column(h.CSV, i))
+}
+
+func makeSortKey(v string, typ ColumnType) sortKey {
+	if isNull(v) {
+		return sortKey{null: true}
+	}
+	switch typ {
+	case TypeNumeric:
+		f, ok := ParseNumber(v)
+		return sortKey{null: !ok, num: f}
+	case TypeDate:
+		t, ok := ParseDate(v)
+		return sortKey{null: !ok, t: t}
+	}
+	return sortKey{s: v}
+}
+
+// compareSortKeys compares two sort keys of the received type; text is
+// compared using the collator, if there is one, otherwise in natural order.
+func compareSortKeys(a, b sortKey, typ ColumnType, collator Collator) int {
+	switch typ {
+	case TypeNumeric:
+		switch {
+		case a.num < b.num:
+			return -1
+		case a.num > b.num:
+			return 1
+		}
+		return 0
+	case TypeDate:
+		switch {
+		case a.t.Before(b.t):
+			return -1
+		case a.t.After(b.t):
+			return 1
+		}
+		return 0
+	}
+	if collator != nil {
+		return collator.CompareString(a.s, b.s)
+	}
+	return NaturalCompare(a.s, b.s)
+}
+
+// NaturalCompare compares two strings in natural order: runs of digits are
+// compared by their numeric value, so "file2" sorts before "file10", and
+// letters are compared case-insensitively.  Strings that are equal under
+// those rules are compared byte-wise.
+func NaturalCompare(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			da := strings.TrimLeft(a[si:i], "0")
+			db := strings.TrimLeft(b[sj:j], "0")
+			if len(da) != len(db) {
+				if len(da) < len(db) {
+					return -1
+				}
+				return 1
+			}
+			if c := strings.Compare(da, db); c != 0 {
+				return c
+			}
+			continue
+		}
+		ra, wa := utf8.DecodeRuneInString(a[i:])
+		rb, wb := utf8.DecodeRuneInString(b[j:])
+		ra, rb = unicode.ToLower(ra), unicode.ToLower(rb)
+		if ra != rb {
+			if ra < rb {
+				return -1
+			}
+			return 1
+		}
+		i += wa
+		j += wb
+	}
+	switch {
+	case len(a)-i < len(b)-j:
+		return -1
+	case len(a)-i > len(b)-j:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,156 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	json "github.com/mohae/unsafejson"
+)
+
+func TestParseSort(t *testing.T) {
+	keys, err := ParseSort("Region, -Revenue,+2")
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	expected := []SortKey{{Column: "Region"}, {Column: "Revenue", Desc: true}, {Column: "2"}}
+	if json.MarshalToString(keys) != json.MarshalToString(expected) {
+		t.Errorf("got %v; want %v", keys, expected)
+	}
+}
+
+func TestNaturalCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"file2", "file10", -1},
+		{"file10", "file2", 1},
+		{"File2", "file2", -1},
+		{"file02", "file2", -1},
+		{"abc", "ABD", -1},
+		{"a", "ab", -1},
+		{"x1y", "x1y", 0},
+	}
+	for i, test := range tests {
+		c := NaturalCompare(test.a, test.b)
+		if c != test.expected {
+			t.Errorf("%d: %q, %q: got %d; want %d", i, test.a, test.b, c, test.expected)
+		}
+	}
+}
+
+// reverseCollator compares strings in reverse byte order.
+type reverseCollator struct{}
+
+func (reverseCollator) CompareString(a, b string) int {
+	return strings.Compare(b, a)
+}
+
+func TestSortRecords(t *testing.T) {
+	tests := []struct {
+		SortBy      []SortKey
+		Collator    Collator
+		Expected    string // the names, in order
+		ExpectedErr string
+	}{
+		{ // 0
+			SortBy:   []SortKey{{Column: "Revenue"}},
+			Expected: "Dan,Bob,Carl,Alice,Eve",
+		},
+		{ // 1
+			SortBy:   []SortKey{{Column: "Revenue", Desc: true}},
+			Expected: "Alice,Carl,Bob,Dan,Eve",
+		},
+		{ // 2
+			SortBy:   []SortKey{{Column: "Region"}, {Column: "Revenue"}},
+			Expected: "Dan,Alice,Carl,Bob,Eve",
+		},
+		{ // 3
+			SortBy:   []SortKey{{Column: "Joined"}},
+			Expected: "Carl,Alice,Eve,Bob,Dan",
+		},
+		{ // 4
+			SortBy:   []SortKey{{Column: "Region"}},
+			Expected: "Alice,Dan,Carl,Bob,Eve",
+		},
+		{ // 5
+			SortBy:   []SortKey{{Column: "0"}},
+			Collator: reverseCollator{},
+			Expected: "Eve,Dan,Carl,Bob,Alice",
+		},
+		{ // 6
+			SortBy:      []SortKey{{Column: "Total"}},
+			ExpectedErr: `unknown column: "Total"`,
+		},
+	}
+	h := New("test")
+	for i, test := range tests {
+		h.Reset()
+		h.SortBy = test.SortBy
+		h.Collator = test.Collator
+		h.CSV = [][]string{
+			[]string{"Name", "Region", "Revenue", "Joined"},
+			[]string{"Alice", "east", "1,200", "2015-03-01"},
+			[]string{"Bob", "region10", "90", "2016-01-15"},
+			[]string{"Carl", "region9", "900", "2014-12-31"},
+			[]string{"Dan", "east", "9", ""},
+			[]string{"Eve", "", "", "2015-06-30"},
+		}
+		err := h.process()
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q: want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		var names []string
+		for _, rec := range h.CSV {
+			names = append(names, rec[0])
+		}
+		if strings.Join(names, ",") != test.Expected {
+			t.Errorf("%d: got %q; want %q", i, strings.Join(names, ","), test.Expected)
+		}
+	}
+}
+
+func TestSortAriaSort(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.SortBy = []SortKey{{Column: "Revenue", Desc: true}}
+	h.Columns = []string{"Name", "Revenue"}
+	h.CSV = [][]string{
+		[]string{"Region", "Revenue", "Name"},
+		[]string{"east", "10", "Alice"},
+		[]string{"west", "20", "Bob"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	expected := `
+<table class="test" border="">
+    <thead>
+        <tr>
+            <th scope="col">Name</th>
+            <th scope="col" aria-sort="descending">Revenue</th>
+        </tr>
+    </thead>
+    <tbody>
+        <tr>
+            <td>Bob</td>
+            <td>20</td>
+        </tr>
+        <tr>
+            <td>Alice</td>
+            <td>10</td>
+        </tr>
+    </tbody>
+</table>
+`
+	if buf.String() != expected {
+		t.Errorf("got %q; want %q", buf.String(), expected)
+	}
+}
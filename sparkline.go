@@ -0,0 +1,266 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errSparkline = errors.New("invalid sparkline")
+
+// SparklineType is the type of chart that a sparkline is.
+type SparklineType int
+
+// Supported sparkline types.
+const (
+	SparklineLine SparklineType = iota
+	SparklineBar
+)
+
+// Sparkline adds a column to the table whose cells are inline SVG charts of
+// a range of each record's numeric columns, e.g. a metric's value for each
+// week.  The column is added after the other columns; its values, as
+// written in the other output formats, are the plotted values separated by
+// spaces, with - for values that aren't numbers.  The smallest and largest
+// values are marked, and each chart has a title, for assistive technology,
+// that lists its values.
+type Sparkline struct {
+	// The header of the sparkline's column; if empty, "Trend" is used.
+	Header string
+	// The keys of the first and last columns of the range of columns whose
+	// values are plotted.
+	From, To string
+	// The type of chart.
+	Type SparklineType
+	// The chart's dimensions, in pixels; if 0, 100 by 20 is used.
+	Width, Height int
+	// The color of the line or bars; if empty, the text color is used.
+	Color string
+}
+
+// sparkline is a Sparkline resolved against the output columns.
+type sparkline struct {
+	Sparkline
+	from, to string // the headers of the range's first and last columns
+}
+
+// The default sparkline dimensions and the colors of its markers.
+const (
+	sparkWidth    = 100
+	sparkHeight   = 20
+	sparkMinColor = "#d9534f"
+	sparkMaxColor = "#3c8d40"
+)
+
+// addSparklines adds a column to the header rows and records for each
+// Sparkline, whose values are the values of the sparkline's range.
+func (h *HTMLTable) addSparklines() error {
+	h.sparks = nil
+	if len(h.Sparklines) == 0 {
+		return nil
+	}
+	if h.Transpose {
+		return fmt.Errorf("%s: a transposed table can't have sparklines", errSparkline)
+	}
+	for _, sl := range h.Sparklines {
+		from, to := h.columnIndex(sl.From), h.columnIndex(sl.To)
+		if from < 0 {
+			return unknownColumnErr(sl.From)
+		}
+		if to < 0 {
+			return unknownColumnErr(sl.To)
+		}
+		if from > to {
+			return fmt.Errorf("%s: %q is after %q", errSparkline, sl.From, sl.To)
+		}
+		s := &sparkline{Sparkline: sl}
+		if s.Header == "" {
+			s.Header = "Trend"
+		}
+		if len(h.names) > to {
+			s.from, s.to = h.names[from], h.names[to]
+		}
+		for i, rec := range h.CSV {
+			vals := make([]string, 0, to-from+1)
+			for j := from; j <= to; j++ {
+				f, ok := ParseNumber(field(rec, j))
+				if !ok {
+					vals = append(vals, "-")
+					continue
+				}
+				vals = append(vals, formatFloat(f))
+			}
+			h.CSV[i] = appendField(rec, h.Cols, strings.Join(vals, " "))
+		}
+		for i, row := range h.HeaderRows {
+			v := ""
+			if i == 0 {
+				v = s.Header
+			}
+			h.HeaderRows[i] = appendField(row, h.Cols, v)
+		}
+		if h.pivotTotal != nil {
+			h.pivotTotal = appendField(h.pivotTotal, h.Cols, "")
+		}
+		if h.sparks == nil {
+			h.sparks = make(map[int]*sparkline)
+		}
+		h.sparks[h.Cols] = s
+		h.srcIdx = append(h.srcIdx, -1)
+		h.Cols++
+	}
+	if len(h.HeaderRows) > 0 {
+		h.names = h.HeaderRows[0]
+	}
+	return nil
+}
+
+// appendField returns a copy of the row, padded with empty fields to width,
+// with v appended, so that v is the row's width'th field even if the row is
+// short; the row is copied so that the received CSV isn't modified.
+func appendField(row []string, width int, v string) []string {
+	r := make([]string, width, width+1)
+	copy(r, row)
+	return append(r, v)
+}
+
+// Render implements Renderer; v holds the values, separated by spaces.
+func (s *sparkline) Render(v string, field func(k string) string) template.HTML {
+	fields := strings.Fields(v)
+	if len(fields) == 0 {
+		return ""
+	}
+	vals := make([]float64, len(fields))
+	lo, hi := math.Inf(1), math.Inf(-1)
+	minIdx, maxIdx := -1, -1
+	for i, f := range fields {
+		n, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			vals[i] = math.NaN()
+			continue
+		}
+		vals[i] = n
+		if n < lo {
+			lo, minIdx = n, i
+		}
+		if n > hi {
+			hi, maxIdx = n, i
+		}
+	}
+	w, ht := s.Width, s.Height
+	if w <= 0 {
+		w = sparkWidth
+	}
+	if ht <= 0 {
+		ht = sparkHeight
+	}
+	color := s.Color
+	if color == "" {
+		color = "currentColor"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="sparkline" width="%d" height="%d" viewBox="0 0 %d %d" role="img">`, w, ht, w, ht)
+	b.WriteString("<title>" + template.HTMLEscapeString(s.title(fields, lo, hi, minIdx)) + "</title>")
+	if minIdx >= 0 {
+		if s.Type == SparklineBar {
+			s.bars(&b, vals, w, ht, lo, hi, minIdx, maxIdx, color)
+		} else {
+			s.line(&b, vals, w, ht, lo, hi, minIdx, maxIdx, color)
+		}
+	}
+	b.WriteString("</svg>")
+	return template.HTML(b.String())
+}
+
+// title returns the chart's title: its range, values, and the smallest and
+// largest values.
+func (s *sparkline) title(fields []string, lo, hi float64, minIdx int) string {
+	var b strings.Builder
+	if s.from != "" {
+		b.WriteString(s.from + " to " + s.to + ": ")
+	}
+	b.WriteString(strings.Join(fields, ", "))
+	if minIdx >= 0 {
+		b.WriteString("; min " + formatFloat(lo) + ", max " + formatFloat(hi))
+	}
+	return b.String()
+}
+
+// The padding around the plot, which leaves room for the markers.
+const sparkPad = 2
+
+// line writes a line chart of the values; values that aren't numbers are
+// gaps in the line.
+func (s *sparkline) line(b *strings.Builder, vals []float64, w, ht int, lo, hi float64, minIdx, maxIdx int, color string) {
+	x := func(i int) float64 {
+		if len(vals) == 1 {
+			return float64(w) / 2
+		}
+		return sparkPad + float64(i)*(float64(w)-2*sparkPad)/float64(len(vals)-1)
+	}
+	y := func(v float64) float64 {
+		if hi == lo {
+			return float64(ht) / 2
+		}
+		return sparkPad + (hi-v)/(hi-lo)*(float64(ht)-2*sparkPad)
+	}
+	var d []string
+	move := true
+	for i, v := range vals {
+		if math.IsNaN(v) {
+			move = true
+			continue
+		}
+		cmd := "L"
+		if move {
+			cmd, move = "M", false
+		}
+		d = append(d, cmd+svgNum(x(i))+" "+svgNum(y(v)))
+	}
+	b.WriteString(`<path d="` + strings.Join(d, " ") + `" fill="none" stroke="` + template.HTMLEscapeString(color) + `" stroke-width="1.5" stroke-linecap="round"></path>`)
+	fmt.Fprintf(b, `<circle class="sparkline-min" cx="%s" cy="%s" r="2" fill="%s"></circle>`, svgNum(x(minIdx)), svgNum(y(vals[minIdx])), sparkMinColor)
+	fmt.Fprintf(b, `<circle class="sparkline-max" cx="%s" cy="%s" r="2" fill="%s"></circle>`, svgNum(x(maxIdx)), svgNum(y(vals[maxIdx])), sparkMaxColor)
+}
+
+// bars writes a bar chart of the values; the bars start at zero and values
+// that aren't numbers don't have a bar.
+func (s *sparkline) bars(b *strings.Builder, vals []float64, w, ht int, lo, hi float64, minIdx, maxIdx int, color string) {
+	lo, hi = math.Min(lo, 0), math.Max(hi, 0)
+	y := func(v float64) float64 {
+		if hi == lo {
+			return float64(ht)
+		}
+		return (hi - v) / (hi - lo) * float64(ht)
+	}
+	step := float64(w) / float64(len(vals))
+	zero := y(0)
+	for i, v := range vals {
+		if math.IsNaN(v) {
+			continue
+		}
+		top, bottom := math.Min(y(v), zero), math.Max(y(v), zero)
+		class, fill := "", color
+		switch i {
+		case minIdx:
+			class, fill = ` class="sparkline-min"`, sparkMinColor
+		case maxIdx:
+			class, fill = ` class="sparkline-max"`, sparkMaxColor
+		}
+		fmt.Fprintf(b, `<rect%s x="%s" y="%s" width="%s" height="%s" fill="%s"></rect>`,
+			class, svgNum(float64(i)*step+step*0.1), svgNum(top), svgNum(step*0.8), svgNum(math.Max(bottom-top, 0.5)), template.HTMLEscapeString(fill))
+	}
+}
+
+// svgNum formats a coordinate, rounded to one decimal place.
+func svgNum(f float64) string {
+	return formatFloat(math.Round(f*10) / 10)
+}
+
+// IsSparklineErr returns whether or not the error was a result of an invalid
+// Sparkline.
+func IsSparklineErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errSparkline.Error())
+}
@@ -0,0 +1,137 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSparklines(t *testing.T) {
+	tests := []struct {
+		Sparklines  []Sparkline
+		CSV         [][]string
+		Expected    []string
+		ExpectedErr string
+	}{
+		{ // 0
+			Sparklines: []Sparkline{{From: "W1", To: "W4"}},
+			Expected: []string{
+				`<th scope="col">Trend</th>`,
+				`<td><svg class="sparkline" width="100" height="20" viewBox="0 0 100 20" role="img"><title>W1 to W4: 3, 5, 1, 4; min 1, max 5</title>` +
+					`<path d="M2 10 L34 2 L66 18 L98 6" fill="none" stroke="currentColor" stroke-width="1.5" stroke-linecap="round"></path>` +
+					`<circle class="sparkline-min" cx="66" cy="18" r="2" fill="#d9534f"></circle>` +
+					`<circle class="sparkline-max" cx="34" cy="2" r="2" fill="#3c8d40"></circle></svg></td>`,
+				`<path d="M2 18 L34 2 M98 10" fill="none"`,
+			},
+		},
+		{ // 1
+			Sparklines: []Sparkline{{Header: "Weekly", From: "W1", To: "W2", Type: SparklineBar, Width: 20, Height: 10, Color: "#00f"}},
+			Expected: []string{
+				`<th scope="col">Weekly</th>`,
+				`<td><svg class="sparkline" width="20" height="10" viewBox="0 0 20 10" role="img"><title>W1 to W2: 3, 5; min 3, max 5</title>` +
+					`<rect class="sparkline-min" x="1" y="4" width="8" height="6" fill="#d9534f"></rect>` +
+					`<rect class="sparkline-max" x="11" y="0" width="8" height="10" fill="#3c8d40"></rect></svg></td>`,
+			},
+		},
+		{ // 2
+			Sparklines: []Sparkline{{From: "W1", To: "W1"}, {Header: "Later", From: "W3", To: "W4"}},
+			Expected: []string{
+				`<th scope="col">Trend</th>
+            <th scope="col">Later</th>`,
+				"<title>W1 to W1: 3; min 3, max 3</title>",
+				"<title>W3 to W4: 1, 4; min 1, max 4</title>",
+			},
+		},
+		{ // 3
+			Sparklines:  []Sparkline{{From: "W4", To: "W1"}},
+			ExpectedErr: `invalid sparkline: "W4" is after "W1"`,
+		},
+		{ // 4
+			Sparklines:  []Sparkline{{From: "W1", To: "W9"}},
+			ExpectedErr: `unknown column: "W9"`,
+		},
+		{ // 5
+			Sparklines: []Sparkline{{From: "W1", To: "W3"}},
+			CSV: [][]string{
+				[]string{"Metric", "W1", "W2", "W3"},
+				[]string{"a", "1", "2", "3"},
+				[]string{"b", "4", "5"},
+			},
+			Expected: []string{
+				`<td>5</td>
+            <td></td>
+            <td><svg class="sparkline" width="100" height="20" viewBox="0 0 100 20" role="img"><title>W1 to W3: 4, 5, -; min 4, max 5</title>`,
+			},
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.Sparklines = test.Sparklines
+		h.CSV = test.CSV
+		if h.CSV == nil {
+			h.CSV = [][]string{
+				[]string{"Metric", "W1", "W2", "W3", "W4"},
+				[]string{"Visits", "3", "5", "1", "4"},
+				[]string{"Signups", "0", "10", "", "5"},
+			}
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			if test.ExpectedErr == "" {
+				t.Errorf("%d: got %q; want nil", i, err)
+			} else if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got nil; want %q", i, test.ExpectedErr)
+			continue
+		}
+		for _, v := range test.Expected {
+			if !strings.Contains(buf.String(), v) {
+				t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), v)
+			}
+		}
+	}
+}
+
+func TestSparklineCSV(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.Sparklines = []Sparkline{{From: "W1", To: "W3"}}
+	h.CSV = [][]string{
+		[]string{"Metric", "W1", "W2", "W3"},
+		[]string{"Visits", "1,000", "n/a", "12.5"},
+	}
+	err := h.WriteCSV(&buf)
+	if err != nil {
+		t.Fatalf("got %q; want nil", err)
+	}
+	want := "Metric,W1,W2,W3,Trend\nVisits,\"1,000\",n/a,12.5,1000 - 12.5\n"
+	if buf.String() != want {
+		t.Errorf("got %q; want %q", buf.String(), want)
+	}
+}
+
+func TestSparklineErrs(t *testing.T) {
+	h := New("test")
+	h.Transpose = true
+	h.Sparklines = []Sparkline{{From: "a", To: "b"}}
+	h.CSV = [][]string{{"a", "b"}, {"1", "2"}}
+	err := h.Write(&bytes.Buffer{})
+	if err == nil || !IsSparklineErr(err) {
+		t.Errorf("transpose: got %v; want a sparkline error", err)
+	}
+	h.Reset()
+	h.Sparklines = []Sparkline{{From: "a", To: "b"}}
+	h.HeaderRows = [][]string{{"a", "b"}}
+	h.Rows = []Row{{Cells: []Cell{{Text: "1"}, {Text: "2"}}}}
+	err = h.Write(&bytes.Buffer{})
+	if err == nil || !IsSparklineErr(err) {
+		t.Errorf("rows: got %v; want a sparkline error", err)
+	}
+}
@@ -0,0 +1,299 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+var errUnknownTheme = errors.New("unknown theme")
+
+// Theme maps the parts of a table to the classes of a stylesheet, e.g. a CSS
+// framework's.  Each field holds the space separated classes of its part,
+// which are added to any classes the part already has, e.g. the table's
+// Class or a group's header row's group class.
+//
+// EvenRow and OddRow are added to the body rows by their position among their
+// group's rows, counting from 1, when the table is written; client-side
+// sorting and filtering don't update them, so the built-in themes stripe
+// their rows with their stylesheets instead.
+type Theme struct {
+	Name    string
+	Table   string
+	Caption string
+	THead   string
+	TBody   string
+	TFoot   string
+	TR      string // every row, including the header and footer rows
+	TH      string
+	TD      string
+	EvenRow string
+	OddRow  string
+	Hover   string // the body rows, for highlighting them on hover
+	// The class that visually hides text that's only for assistive
+	// technology, e.g. BoolIcon's text; if empty, "visually-hidden" is used.
+	HiddenClass string
+	// The theme's stylesheet, which is trusted and written as is if the
+	// table's IncludeCSS is set; themes for CSS frameworks don't have one.
+	CSS string
+	// The text and background colors of the theme's parts, which Lint checks
+	// for contrast.
+	Colors []ThemeColor
+}
+
+// ThemeColor is the text color and background color, in #rgb or #rrggbb
+// form, of a part of a table, e.g. its header cells or its striped rows.
+type ThemeColor struct {
+	Part       string
+	Color      string
+	Background string
+}
+
+// DefaultCSS is a self-contained stylesheet for the bare and striped themes,
+// which also visually hides the visually-hidden text of renderers such as
+// BoolIcon.  Its rules are scoped to the csv2htmltable class so that it can
+// be inlined into any document.
+const DefaultCSS = `.csv2htmltable {
+    border-collapse: collapse;
+    margin: 1em 0;
+    color: #212529;
+    background-color: #fff;
+    font-variant-numeric: tabular-nums;
+}
+.csv2htmltable caption {
+    caption-side: top;
+    padding: 0.5em 0;
+    font-weight: bold;
+    text-align: left;
+}
+.csv2htmltable th,
+.csv2htmltable td {
+    padding: 0.4em 0.75em;
+    border-bottom: 1px solid #dee2e6;
+    text-align: left;
+    vertical-align: top;
+}
+.csv2htmltable thead th {
+    background-color: #e9ecef;
+    border-bottom: 2px solid #adb5bd;
+    vertical-align: bottom;
+}
+.csv2htmltable tfoot th,
+.csv2htmltable tfoot td {
+    border-top: 2px solid #adb5bd;
+    font-weight: bold;
+}
+.csv2htmltable tr.group th {
+    background-color: #f8f9fa;
+}
+.csv2htmltable tr.subtotal th,
+.csv2htmltable tr.subtotal td {
+    font-weight: bold;
+}
+.csv2htmltable.striped > tbody > tr:nth-child(even) {
+    background-color: #f2f2f2;
+}
+.csv2htmltable.striped > tbody > tr:hover {
+    background-color: #e2e6ea;
+}
+.csv2htmltable .visually-hidden {
+    position: absolute;
+    width: 1px;
+    height: 1px;
+    margin: -1px;
+    padding: 0;
+    overflow: hidden;
+    clip: rect(0, 0, 0, 0);
+    white-space: nowrap;
+    border: 0;
+}
+`
+
+// defaultColors are the colors of the DefaultCSS.
+var defaultColors = []ThemeColor{
+	{"body", "#212529", "#fff"},
+	{"header", "#212529", "#e9ecef"},
+	{"group", "#212529", "#f8f9fa"},
+}
+
+// Themes are the built-in themes, by name.  They can be copied and modified,
+// e.g. to add classes to a part.
+var Themes = map[string]*Theme{
+	"bare": {
+		Name:   "bare",
+		Table:  "csv2htmltable",
+		CSS:    DefaultCSS,
+		Colors: defaultColors,
+	},
+	"striped": {
+		Name:  "striped",
+		Table: "csv2htmltable striped",
+		CSS:   DefaultCSS,
+		Colors: append(defaultColors,
+			ThemeColor{"striped rows", "#212529", "#f2f2f2"},
+			ThemeColor{"hovered rows", "#212529", "#e2e6ea"},
+		),
+	},
+	"bootstrap": {
+		Name:        "bootstrap",
+		Table:       "table table-striped table-hover caption-top",
+		THead:       "table-light",
+		TFoot:       "table-group-divider",
+		HiddenClass: "visually-hidden",
+	},
+	"bulma": {
+		Name:        "bulma",
+		Table:       "table is-striped is-hoverable is-fullwidth",
+		HiddenClass: "is-sr-only",
+	},
+	"tailwind": {
+		Name:        "tailwind",
+		Table:       "min-w-full divide-y divide-gray-200 text-sm",
+		Caption:     "py-2 text-left font-semibold text-gray-900",
+		THead:       "bg-gray-50",
+		TBody:       "divide-y divide-gray-200 bg-white [&>tr:nth-child(even)]:bg-gray-50",
+		TFoot:       "bg-gray-50 font-semibold",
+		TH:          "px-4 py-2 text-left font-medium text-gray-900",
+		TD:          "px-4 py-2 text-gray-700",
+		Hover:       "hover:bg-gray-100",
+		HiddenClass: "sr-only",
+	},
+}
+
+// ThemeNames returns the names of the built-in themes, in order.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for k := range Themes {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseTheme returns the built-in theme with the received name, which is
+// case-insensitive.
+func ParseTheme(s string) (*Theme, error) {
+	t, ok := Themes[strings.ToLower(s)]
+	if !ok {
+		return nil, fmt.Errorf("%s: %q; want one of %s", errUnknownTheme, s, strings.Join(ThemeNames(), ", "))
+	}
+	return t, nil
+}
+
+// part returns the Theme's classes for a part of the table, by the name of
+// its element, or, for the body rows, "even", "odd", and "hover".
+func (t *Theme) part(name string) string {
+	if t == nil {
+		return ""
+	}
+	switch name {
+	case "table":
+		return t.Table
+	case "caption":
+		return t.Caption
+	case "thead":
+		return t.THead
+	case "tbody":
+		return t.TBody
+	case "tfoot":
+		return t.TFoot
+	case "tr":
+		return t.TR
+	case "th":
+		return t.TH
+	case "td":
+		return t.TD
+	case "even":
+		return t.EvenRow
+	case "odd":
+		return t.OddRow
+	case "hover":
+		return t.Hover
+	}
+	return ""
+}
+
+// joinClasses joins the non-empty class lists.
+func joinClasses(classes ...string) string {
+	var b strings.Builder
+	for _, c := range classes {
+		if c == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(c)
+	}
+	return b.String()
+}
+
+// tableClass returns the table's Class and its Theme's table classes.
+func (h *HTMLTable) tableClass() string {
+	return joinClasses(h.Class, h.Theme.part("table"))
+}
+
+// classAttr returns the class attribute of a part of the table: the base
+// classes, if there are any, followed by the Theme's classes for the part.
+// If there aren't any classes, it's empty.
+func (h *HTMLTable) classAttr(part string, base ...string) template.HTMLAttr {
+	c := joinClasses(append(base, h.Theme.part(part))...)
+	if c == "" {
+		return ""
+	}
+	return attrs{{"class", c}}.HTMLAttr()
+}
+
+// cellClass returns the Theme's classes of a th or td, followed by base.
+func (h *HTMLTable) cellClass(header bool, base string) string {
+	if header {
+		return joinClasses(h.Theme.part("th"), base)
+	}
+	return joinClasses(h.Theme.part("td"), base)
+}
+
+// rowClass returns the Theme's classes of the i'th row of a tbody, counting
+// from 0, followed by base.
+func (h *HTMLTable) rowClass(i int, base string) string {
+	stripe := "odd"
+	if i%2 == 1 {
+		stripe = "even"
+	}
+	return joinClasses(h.Theme.part("tr"), h.Theme.part(stripe), h.Theme.part("hover"), base)
+}
+
+// css returns the Theme's stylesheet, if IncludeCSS is set.
+func (h *HTMLTable) css() template.CSS {
+	if !h.IncludeCSS || h.Theme == nil {
+		return ""
+	}
+	return template.CSS(h.Theme.CSS)
+}
+
+// lintTheme checks the contrast of the Theme's colors against WCAG AA's
+// minimum for normal text.
+func (h *HTMLTable) lintTheme() []Finding {
+	if h.Theme == nil {
+		return nil
+	}
+	var fs []Finding
+	for _, c := range h.Theme.Colors {
+		r, err := ContrastRatio(c.Color, c.Background)
+		if err != nil {
+			fs = append(fs, Finding{SeverityError, RuleContrast, fmt.Sprintf("the theme's %s colors: %s", c.Part, err)})
+			continue
+		}
+		if r < 4.5 {
+			fs = append(fs, Finding{SeverityWarning, RuleContrast, fmt.Sprintf("the theme's %s text, %s on %s, has a contrast ratio of %.2f; want at least 4.5", c.Part, c.Color, c.Background, r)})
+		}
+	}
+	return fs
+}
+
+// IsUnknownThemeErr returns whether or not the error was a result of a theme
+// name that isn't one of the built-in themes.
+func IsUnknownThemeErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errUnknownTheme.Error())
+}
@@ -0,0 +1,281 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestThemes(t *testing.T) {
+	custom := &Theme{TR: "row", TH: "head", TD: "cell", EvenRow: "even", OddRow: "odd", Hover: "hl"}
+	tests := []struct {
+		Theme      *Theme
+		IncludeCSS bool
+		Rules      []Rule
+		Expected   []string
+	}{
+		{ // 0
+			Theme: nil,
+			Expected: []string{
+				`<table class="test" border="">`,
+				"<caption>Scores</caption>",
+				"<thead>",
+				`<th scope="col">Name</th>`,
+				"<tbody>",
+				"<td>3</td>",
+			},
+		},
+		{ // 1
+			Theme: Themes["bootstrap"],
+			Expected: []string{
+				`<table class="test table table-striped table-hover caption-top" border="">`,
+				`<thead class="table-light">`,
+				`<tfoot class="table-group-divider">`,
+				"<tbody>",
+			},
+		},
+		{ // 2
+			Theme: Themes["tailwind"],
+			Expected: []string{
+				`<caption class="py-2 text-left font-semibold text-gray-900">Scores</caption>`,
+				`<th scope="col" class="px-4 py-2 text-left font-medium text-gray-900">Name</th>`,
+				`<tr class="hover:bg-gray-100">
+            <td class="px-4 py-2 text-gray-700">Bob</td>`,
+				`<td class="px-4 py-2 text-gray-700">7</td>`,
+			},
+		},
+		{ // 3
+			Theme: custom,
+			Rules: []Rule{{Column: "Score", When: "Score > 3", Class: "high"}},
+			Expected: []string{
+				`<tr class="row">
+            <th scope="col" class="head">Name</th>`,
+				`<tr class="row odd hl">
+            <td class="cell">Bob</td>
+            <td class="cell">3</td>`,
+				`<tr class="row even hl">
+            <td class="cell">Ann</td>
+            <td class="cell high">4</td>`,
+				`<tr class="row">
+            <td class="cell">Total</td>
+            <td class="cell">7</td>
+        </tr>
+    </tfoot>`,
+			},
+		},
+		{ // 4
+			Theme:      Themes["striped"],
+			IncludeCSS: true,
+			Expected: []string{
+				"<style>\n.csv2htmltable {",
+				"}\n</style>\n<table",
+				`<table class="test csv2htmltable striped" border="">`,
+			},
+		},
+		{ // 5
+			Theme:      Themes["bulma"],
+			IncludeCSS: true,
+			Expected:   []string{`<table class="test table is-striped is-hoverable is-fullwidth" border="">`},
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.Caption = "Scores"
+		h.Theme = test.Theme
+		h.IncludeCSS = test.IncludeCSS
+		h.Rules = test.Rules
+		h.FooterRows = []FooterRow{{Label: "Total", Aggregates: map[string]Aggregate{"Score": AggSum}}}
+		h.CSV = [][]string{
+			[]string{"Name", "Score"},
+			[]string{"Bob", "3"},
+			[]string{"Ann", "4"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		got := buf.String()
+		for _, want := range test.Expected {
+			if !strings.Contains(got, want) {
+				t.Errorf("%d: got %q; want it to contain %q", i, got, want)
+			}
+		}
+		css := test.IncludeCSS && test.Theme.CSS != ""
+		if strings.Contains(got, "<style>") != css {
+			t.Errorf("%d: got %q; want the stylesheet to be included: %t", i, got, css)
+		}
+	}
+}
+
+func TestThemeRows(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.Theme = &Theme{TH: "head", TD: "cell", EvenRow: "even"}
+	h.HasRowHeader = true
+	h.HeaderRows = [][]string{[]string{"Name", "Score"}}
+	h.Rows = []Row{
+		{Cells: []Cell{{Text: "Bob"}, {Text: "3", Class: "x"}}},
+		{Class: "last", Cells: []Cell{{Text: "Ann", Header: true}, {Text: "4"}}},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{
+		`<tr>
+            <td class="cell">Bob</td>
+            <td class="cell x">3</td>`,
+		`<tr class="even last">
+            <th scope="row" class="head">Ann</th>
+            <td class="cell">4</td>`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("got %q; want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestThemeStyleNonce(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.Theme = Themes["bare"]
+	h.IncludeCSS = true
+	h.StyleNonce = "abc123"
+	h.CSV = [][]string{
+		[]string{"Name", "Score"},
+		[]string{"Bob", "3"},
+	}
+	err := h.Write(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `<style nonce="abc123">`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got %q; want it to contain %q", buf.String(), want)
+	}
+}
+
+func TestThemeHiddenClass(t *testing.T) {
+	tests := []struct {
+		Theme    *Theme
+		Icon     Renderer
+		Expected string
+	}{
+		{Themes["striped"], BoolIcon{}, `<span class="visually-hidden">Yes</span>`},
+		{Themes["bootstrap"], BoolIcon{}, `<span class="visually-hidden">Yes</span>`},
+		{Themes["bulma"], BoolIcon{}, `<span class="is-sr-only">Yes</span>`},
+		{Themes["tailwind"], BoolIcon{}, `<span class="sr-only">Yes</span>`},
+		{Themes["tailwind"], BoolIcon{HiddenClass: "hide"}, `<span class="hide">Yes</span>`},
+		{Themes["bulma"], &BoolIcon{}, `<span class="is-sr-only">Yes</span>`},
+		{Themes["bulma"], &BoolIcon{HiddenClass: "hide"}, `<span class="hide">Yes</span>`},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.Theme = test.Theme
+		h.Renderers = map[string]Renderer{"Active": test.Icon}
+		h.CSV = [][]string{
+			[]string{"Name", "Active"},
+			[]string{"Bob", "true"},
+		}
+		err := h.Write(&buf)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if !strings.Contains(buf.String(), test.Expected) {
+			t.Errorf("%d: got %q; want it to contain %q", i, buf.String(), test.Expected)
+		}
+		if b, ok := test.Icon.(*BoolIcon); ok && b.HiddenClass == test.Theme.HiddenClass {
+			t.Errorf("%d: got HiddenClass %q; want the renderer to be unmodified", i, b.HiddenClass)
+		}
+	}
+	if !strings.Contains(DefaultCSS, ".csv2htmltable .visually-hidden {") {
+		t.Errorf("DefaultCSS doesn't hide the visually-hidden class")
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	tests := []struct {
+		Value       string
+		Expected    string
+		ExpectedErr string
+	}{
+		{"bare", "bare", ""},
+		{"Striped", "striped", ""},
+		{"bootstrap", "bootstrap", ""},
+		{"bulma", "bulma", ""},
+		{"tailwind", "tailwind", ""},
+		{"foundation", "", `unknown theme: "foundation"; want one of bare, bootstrap, bulma, striped, tailwind`},
+	}
+	for i, test := range tests {
+		th, err := ParseTheme(test.Value)
+		if err != nil {
+			if err.Error() != test.ExpectedErr {
+				t.Errorf("%d: got %q; want %q", i, err, test.ExpectedErr)
+			} else if !IsUnknownThemeErr(err) {
+				t.Errorf("%d: expected IsUnknownThemeErr to be true", i)
+			}
+			continue
+		}
+		if test.ExpectedErr != "" {
+			t.Errorf("%d: got no error; want %q", i, test.ExpectedErr)
+			continue
+		}
+		if th.Name != test.Expected {
+			t.Errorf("%d: got %q; want %q", i, th.Name, test.Expected)
+		}
+	}
+}
+
+func TestLintTheme(t *testing.T) {
+	tests := []struct {
+		Theme    *Theme
+		Expected []string
+	}{
+		{ // 0
+			Theme:    Themes["striped"],
+			Expected: nil,
+		},
+		{ // 1
+			Theme:    Themes["bootstrap"],
+			Expected: nil,
+		},
+		{ // 2
+			Theme: &Theme{Colors: []ThemeColor{
+				{"body", "#777", "#fff"},
+				{"header", "#000", "#fff"},
+				{"footer", "gray", "#fff"},
+			}},
+			Expected: []string{
+				`error: contrast: the theme's footer colors: invalid color "gray": expected #rgb or #rrggbb`,
+				"warning: contrast: the theme's body text, #777 on #fff, has a contrast ratio of 4.48; want at least 4.5",
+			},
+		},
+	}
+	h := New("test")
+	for i, test := range tests {
+		h.Reset()
+		h.Caption = "Scores"
+		h.Theme = test.Theme
+		h.CSV = [][]string{
+			[]string{"Name", "Score"},
+			[]string{"Bob", "3"},
+		}
+		var got []string
+		for _, f := range h.Lint() {
+			got = append(got, f.String())
+		}
+		if !reflect.DeepEqual(got, test.Expected) {
+			t.Errorf("%d: got %q; want %q", i, got, test.Expected)
+		}
+	}
+}
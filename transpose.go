@@ -0,0 +1,100 @@
+package csv2htmltable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errTranspose = errors.New("invalid transpose")
+
+// transpose flips the table's rows and columns, if Transpose is set.  The
+// i'th column becomes the i'th record, starting with the column's header
+// from each header row, followed by its value from each record.  Because the
+// header rows become row header columns, the transposed table doesn't have
+// any header rows.  A pivot's column totals are transposed along with the
+// records, as the last column.
+//
+// The columns' keys don't refer to the transposed table's columns, so the
+// Formats are applied to the values as they are flipped and the other
+// options that are keyed by column, which are resolved later, result in an
+// error.
+func (h *HTMLTable) transpose() error {
+	h.rowHeads = 0
+	if !h.Transpose {
+		return nil
+	}
+	if opts := h.columnOptions(); len(opts) > 0 {
+		return fmt.Errorf("%s: a transposed table can't have %s", errTranspose, strings.Join(opts, ", "))
+	}
+	rows := make([][]string, 0, len(h.HeaderRows)+len(h.CSV)+1)
+	rows = append(rows, h.HeaderRows...)
+	rows = append(rows, h.CSV...)
+	if h.pivotTotal != nil {
+		rows = append(rows, h.pivotTotal)
+		h.pivotTotal = nil
+	}
+	// The values, but not the headers, are formatted as they are flipped; the
+	// received records aren't modified.
+	records := make([][]string, h.Cols)
+	for i := range records {
+		records[i] = make([]string, len(rows))
+		for j, row := range rows {
+			if j < len(h.HeaderRows) {
+				records[i][j] = field(row, i)
+			} else {
+				records[i][j] = h.formatField(i, field(row, i))
+			}
+		}
+	}
+	h.rowHeads = len(h.HeaderRows)
+	h.HeaderRows = nil
+	h.CSV = records
+	h.Cols = len(rows)
+	h.names = nil
+	h.srcIdx = nil
+	h.sortCol = -1
+	// The values have already been formatted.
+	h.formats = map[int]Formatter{}
+	return nil
+}
+
+// columnOptions returns the names of the options that are set which are
+// keyed by column and resolved when the table is rendered.
+func (h *HTMLTable) columnOptions() []string {
+	var opts []string
+	add := func(name string, set bool) {
+		if set {
+			opts = append(opts, name)
+		}
+	}
+	add("GroupBy", len(h.GroupBy) > 0)
+	add("Subtotals", len(h.Subtotals) > 0)
+	add("FooterRows", len(h.FooterRows) > 0)
+	add("MergeColumns", len(h.MergeColumns) > 0)
+	add("ContentModes", len(h.ContentModes) > 0)
+	add("Links", len(h.Links) > 0)
+	add("Renderers", len(h.Renderers) > 0)
+	add("Rules", len(h.Rules) > 0)
+	add("ColorScales", len(h.ColorScales) > 0)
+	add("DataBars", len(h.DataBars) > 0)
+	add("ColumnTypes", len(h.ColumnTypes) > 0)
+	add("Chart", h.Chart != nil)
+	return opts
+}
+
+// isRowHeader returns whether the j'th column of the body is a row header
+// column.  When the table is transposed, each of the original header rows is
+// a row header column.
+func (h *HTMLTable) isRowHeader(j int) bool {
+	if h.rowHeads > 0 {
+		return j < h.rowHeads
+	}
+	return j == 0 && h.HasRowHeader
+}
+
+// IsTransposeErr returns whether or not the error was a result of options
+// that can't be used with a transposed table.
+func IsTransposeErr(err error) bool {
+	return strings.HasPrefix(err.Error(), errTranspose.Error())
+}
@@ -0,0 +1,181 @@
+package csv2htmltable
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTranspose(t *testing.T) {
+	tests := []struct {
+		HeaderRowNum int
+		Formats      map[string]Formatter
+		CSV          [][]string
+		Expected     string
+	}{
+		{ // 0
+			HeaderRowNum: 1,
+			CSV: [][]string{
+				[]string{"Host", "Port", "TLS"},
+				[]string{"example.com", "443", "true"},
+			},
+			Expected: `
+<table class="test" border="">
+    <tbody>
+        <tr>
+            <th scope="row">Host</th>
+            <td>example.com</td>
+        </tr>
+        <tr>
+            <th scope="row">Port</th>
+            <td>443</td>
+        </tr>
+        <tr>
+            <th scope="row">TLS</th>
+            <td>true</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 1
+			HeaderRowNum: 2,
+			CSV: [][]string{
+				[]string{"Host", "Port", "TLS"},
+				[]string{"name", "number", "bool"},
+				[]string{"a.example.com", "443", "true"},
+				[]string{"b.example.com", "80", "false"},
+			},
+			Expected: `
+<table class="test" border="">
+    <tbody>
+        <tr>
+            <th scope="row">Host</th>
+            <th scope="row">name</th>
+            <td>a.example.com</td>
+            <td>b.example.com</td>
+        </tr>
+        <tr>
+            <th scope="row">Port</th>
+            <th scope="row">number</th>
+            <td>443</td>
+            <td>80</td>
+        </tr>
+        <tr>
+            <th scope="row">TLS</th>
+            <th scope="row">bool</th>
+            <td>true</td>
+            <td>false</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 2
+			HeaderRowNum: 0,
+			CSV: [][]string{
+				[]string{"example.com", "443"},
+			},
+			Expected: `
+<table class="test" border="">
+    <tbody>
+        <tr>
+            <td>example.com</td>
+        </tr>
+        <tr>
+            <td>443</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+		{ // 3
+			HeaderRowNum: 1,
+			Formats:      map[string]Formatter{"Amount": NumberFormat("$", 2)},
+			CSV: [][]string{
+				[]string{"Name", "Amount"},
+				[]string{"Bob", "1000"},
+			},
+			Expected: `
+<table class="test" border="">
+    <tbody>
+        <tr>
+            <th scope="row">Name</th>
+            <td>Bob</td>
+        </tr>
+        <tr>
+            <th scope="row">Amount</th>
+            <td>$1,000.00</td>
+        </tr>
+    </tbody>
+</table>
+`,
+		},
+	}
+	var buf bytes.Buffer
+	h := New("test")
+	for i, test := range tests {
+		buf.Reset()
+		h.Reset()
+		h.Transpose = true
+		h.Formats = test.Formats
+		h.HeaderRowNum = test.HeaderRowNum
+		h.HasHeader = test.HeaderRowNum > 0
+		h.CSV = test.CSV
+		err := h.Write(&buf)
+		if err != nil {
+			t.Errorf("%d: got %q: want nil", i, err)
+			continue
+		}
+		if buf.String() != test.Expected {
+			t.Errorf("%d got %q; want %q", i, buf.String(), test.Expected)
+		}
+		// The received records aren't modified.
+		if test.Formats != nil && test.CSV[1][1] != "1000" {
+			t.Errorf("%d: the records were modified: %q", i, test.CSV)
+		}
+	}
+}
+
+func TestTransposeErr(t *testing.T) {
+	var buf bytes.Buffer
+	h := New("test")
+	h.Transpose = true
+	h.Links = map[string]Link{"Name": {URL: "/users/{}"}}
+	h.FooterRows = []FooterRow{{Label: "Total", Aggregates: map[string]Aggregate{"Amount": AggSum}}}
+	h.CSV = [][]string{
+		[]string{"Name", "Amount"},
+		[]string{"Bob", "1000"},
+	}
+	err := h.Write(&buf)
+	want := "invalid transpose: a transposed table can't have FooterRows, Links"
+	if err == nil {
+		t.Fatalf("got no error; want %q", want)
+	}
+	if err.Error() != want {
+		t.Errorf("got %q; want %q", err, want)
+	}
+	if !IsTransposeErr(err) {
+		t.Errorf("expected IsTransposeErr to be true")
+	}
+	h.Reset()
+	h.Transpose = true
+	h.ColumnTypes = map[string]ColumnType{"Amount": TypeText}
+	h.CSV = [][]string{
+		[]string{"Name", "Amount"},
+		[]string{"Bob", "1000"},
+	}
+	err = h.Write(&buf)
+	want = "invalid transpose: a transposed table can't have ColumnTypes"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v; want %q", err, want)
+	}
+	h.Reset()
+	h.Transpose = true
+	h.HeaderRows = [][]string{[]string{"Name", "Amount"}}
+	h.Rows = []Row{{Cells: []Cell{{Text: "Bob"}, {Text: "1000"}}}}
+	err = h.Write(&buf)
+	want = "invalid transpose: Rows can't be transposed"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v; want %q", err, want)
+	}
+}
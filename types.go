@@ -0,0 +1,172 @@
+package csv2htmltable
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColumnType is the type of the data in a column.  Unless it is explicitly
+// set using HTMLTable.ColumnTypes, a column's type is inferred from its
+// values.
+type ColumnType int
+
+// Supported column types.
+const (
+	TypeText ColumnType = iota
+	TypeNumeric
+	TypeDate
+)
+
+var columnTypeNames = [...]string{"text", "numeric", "date"}
+
+func (c ColumnType) String() string {
+	if c < 0 || int(c) >= len(columnTypeNames) {
+		return "text"
+	}
+	return columnTypeNames[c]
+}
+
+// DateLayouts are the layouts, in order, that are tried when parsing a value
+// as a date.
+var DateLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"01/02/2006",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+	"January 2, 2006",
+}
+
+// ParseNumber parses s as a number.  Leading and trailing whitespace,
+// thousands separators, a leading currency symbol, and a trailing percent
+// sign are ignored.  Only finite decimal numbers are numbers.
+func ParseNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	neg := false
+	if s[0] == '-' {
+		neg = true
+		s = s[1:]
+	}
+	for _, sym := range []string{"$", "€", "£", "¥"} {
+		if strings.HasPrefix(s, sym) {
+			s = s[len(sym):]
+			break
+		}
+	}
+	s = strings.TrimSuffix(s, "%")
+	s = strings.Replace(s, ",", "", -1)
+	// Only decimal numbers are accepted: not hex floats, NaN, or infinity,
+	// which strconv.ParseFloat also parses.
+	if strings.Trim(s, "0123456789.eE+-") != "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	if neg {
+		f = -f
+	}
+	return f, true
+}
+
+// ParseDate parses s as a date using DateLayouts.
+func ParseDate(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range DateLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseBool parses s as a boolean: true, yes, y, on, and 1 are true; false,
+// no, n, off, and 0 are false.  Case and leading and trailing whitespace are
+// ignored.
+func ParseBool(s string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "t", "yes", "y", "on", "1":
+		return true, true
+	case "false", "f", "no", "n", "off", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// InferType returns the type of the received values.  Empty values are
+// ignored; if all of the values are empty, the type is TypeText.
+func InferType(values []string) ColumnType {
+	numeric, date, n := true, true, 0
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		n++
+		if numeric {
+			_, numeric = ParseNumber(v)
+		}
+		if date {
+			_, date = ParseDate(v)
+		}
+		if !numeric && !date {
+			return TypeText
+		}
+	}
+	switch {
+	case n == 0:
+		return TypeText
+	case numeric:
+		return TypeNumeric
+	case date:
+		return TypeDate
+	}
+	return TypeText
+}
+
+// ColumnType returns the type of the i'th column.  Column types are only
+// available once the table has been processed, e.g. by Write; until then,
+// TypeText is returned.
+func (h *HTMLTable) ColumnType(i int) ColumnType {
+	if i < 0 || i >= len(h.types) {
+		return TypeText
+	}
+	return h.types[i]
+}
+
+// setTypes sets the type of every column, using ColumnTypes where a column's
+// type has been explicitly set and inferring it otherwise.
+func (h *HTMLTable) setTypes() {
+	h.types = make([]ColumnType, h.Cols)
+	for i := range h.types {
+		h.types[i] = InferType(column(h.CSV, i))
+	}
+	for k, t := range h.ColumnTypes {
+		i := h.columnIndex(k)
+		if i >= 0 && i < len(h.types) {
+			h.types[i] = t
+		}
+	}
+}
+
+// column returns the i'th field of each record.
+func column(records [][]string, i int) []string {
+	vals := make([]string, 0, len(records))
+	for _, rec := range records {
+		if i < len(rec) {
+			vals = append(vals, rec[i])
+		}
+	}
+	return vals
+}
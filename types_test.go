@@ -0,0 +1,104 @@
+package csv2htmltable
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected float64
+		ok       bool
+	}{
+		{s: "42", expected: 42, ok: true},
+		{s: " -3.5 ", expected: -3.5, ok: true},
+		{s: "1,234.5", expected: 1234.5, ok: true},
+		{s: "$10", expected: 10, ok: true},
+		{s: "-$1,000", expected: -1000, ok: true},
+		{s: "12%", expected: 12, ok: true},
+		{s: "", ok: false},
+		{s: "abc", ok: false},
+		{s: "NaN", ok: false},
+		{s: "Inf", ok: false},
+		{s: "-infinity", ok: false},
+		{s: "0x1p4", ok: false},
+		{s: "1e400", ok: false},
+		{s: "1.5e3", expected: 1500, ok: true},
+	}
+	for i, test := range tests {
+		f, ok := ParseNumber(test.s)
+		if ok != test.ok {
+			t.Errorf("%d: got %t; want %t", i, ok, test.ok)
+			continue
+		}
+		if f != test.expected {
+			t.Errorf("%d: got %v; want %v", i, f, test.expected)
+		}
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected bool
+		ok       bool
+	}{
+		{s: "true", expected: true, ok: true},
+		{s: " Yes ", expected: true, ok: true},
+		{s: "Y", expected: true, ok: true},
+		{s: "1", expected: true, ok: true},
+		{s: "FALSE", expected: false, ok: true},
+		{s: "off", expected: false, ok: true},
+		{s: "0", expected: false, ok: true},
+		{s: "", ok: false},
+		{s: "maybe", ok: false},
+	}
+	for i, test := range tests {
+		b, ok := ParseBool(test.s)
+		if ok != test.ok {
+			t.Errorf("%d: got %t; want %t", i, ok, test.ok)
+			continue
+		}
+		if b != test.expected {
+			t.Errorf("%d: got %t; want %t", i, b, test.expected)
+		}
+	}
+}
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		values   []string
+		expected ColumnType
+	}{
+		{values: []string{"1", "2.5", "", "-3"}, expected: TypeNumeric},
+		{values: []string{"2016-09-14", "2016-10-01"}, expected: TypeDate},
+		{values: []string{"Sep 14, 2016", "09/14/2016"}, expected: TypeDate},
+		{values: []string{"1", "two"}, expected: TypeText},
+		{values: []string{"", ""}, expected: TypeText},
+		{values: nil, expected: TypeText},
+	}
+	for i, test := range tests {
+		typ := InferType(test.values)
+		if typ != test.expected {
+			t.Errorf("%d: got %s; want %s", i, typ, test.expected)
+		}
+	}
+}
+
+func TestColumnTypes(t *testing.T) {
+	h := New("test")
+	h.ColumnTypes = map[string]ColumnType{"Code": TypeText}
+	h.CSV = [][]string{
+		[]string{"Code", "Amount", "Date"},
+		[]string{"001", "1.5", "2016-09-14"},
+		[]string{"002", "2", "2016-10-01"},
+	}
+	err := h.process()
+	if err != nil {
+		t.Fatalf("got %q: want nil", err)
+	}
+	expected := []ColumnType{TypeText, TypeNumeric, TypeDate}
+	for i, typ := range expected {
+		if h.ColumnType(i) != typ {
+			t.Errorf("%d: got %s; want %s", i, h.ColumnType(i), typ)
+		}
+	}
+}